@@ -6,11 +6,13 @@ import (
 	"os"
 	"strings"
 
+	"sovylang/internal/checker"
 	"sovylang/internal/evaluator"
 	"sovylang/internal/formatter"
 	"sovylang/internal/lexer"
 	"sovylang/internal/library"
 	"sovylang/internal/parser"
+	"sovylang/internal/resolver"
 )
 
 func main() {
@@ -21,6 +23,7 @@ func main() {
 		fmt.Println("  install <biblioteca> Instalar biblioteca")
 		fmt.Println("  list                Listar bibliotecas instaladas")
 		fmt.Println("  --format <arquivo>  Formatar arquivo")
+		fmt.Println("  tipos <arquivo.sl>  Verificar tipos sem executar")
 		fmt.Println("  --help              Mostrar esta ajuda")
 		fmt.Println("  --version           Mostrar versão")
 		os.Exit(1)
@@ -37,7 +40,9 @@ func main() {
 		if len(os.Args) < 3 {
 			fmt.Println("Uso: sovy install <biblioteca>")
 			fmt.Println("Bibliotecas disponíveis:")
-			fmt.Println("  smath - Biblioteca de matemática avançada")
+			for _, lib := range library.AvailableLibraries() {
+				fmt.Printf("  %s - %s\n", lib.Name(), lib.Description())
+			}
 			os.Exit(1)
 		}
 		installLibrary(os.Args[2])
@@ -49,12 +54,18 @@ func main() {
 			os.Exit(1)
 		}
 		formatFile(os.Args[2])
+	case "tipos":
+		if len(os.Args) < 3 {
+			fmt.Println("Uso: sovy tipos <arquivo.sl>")
+			os.Exit(1)
+		}
+		checkFile(os.Args[2])
 	default:
 		if strings.HasSuffix(command, ".sl") {
 			if len(os.Args) > 2 && os.Args[2] == "--format" {
 				formatFile(command)
 			} else {
-				runFile(command)
+				runFile(command, evaluator.Options{AllowShadowing: hasFlag("--permitir-sombreamento")})
 			}
 		} else {
 			fmt.Printf("Comando desconhecido: %s\n", command)
@@ -69,17 +80,22 @@ func showHelp() {
 	fmt.Println()
 	fmt.Println("Uso:")
 	fmt.Println("  sovy <arquivo.sl>          Executar arquivo")
+	fmt.Println("      --permitir-sombreamento  Permitir redeclarar nomes built-in e de bibliotecas")
 	fmt.Println("  sovy install <biblioteca>  Instalar biblioteca")
 	fmt.Println("  sovy list                  Listar bibliotecas instaladas")
 	fmt.Println("  sovy --format <arquivo>    Formatar arquivo")
+	fmt.Println("  sovy tipos <arquivo.sl>    Verificar tipos sem executar")
 	fmt.Println("  sovy --help                Mostrar ajuda")
 	fmt.Println("  sovy --version             Mostrar versão")
 	fmt.Println()
 	fmt.Println("Bibliotecas disponíveis:")
-	fmt.Println("  smath  - Matemática avançada")
+	for _, lib := range library.AvailableLibraries() {
+		fmt.Printf("  %-6s - %s\n", lib.Name(), lib.Description())
+	}
 	fmt.Println()
 	fmt.Println("Exemplos:")
 	fmt.Println("  sovy programa.sl")
+	fmt.Println("  sovy tipos programa.sl")
 	fmt.Println("  sovy install smath")
 	fmt.Println("  sovy list")
 	fmt.Println()
@@ -87,7 +103,7 @@ func showHelp() {
 	fmt.Println("  sovy <biblioteca> include")
 }
 
-func runFile(filename string) {
+func runFile(filename string, options evaluator.Options) {
 	if !fileExists(filename) {
 		fmt.Printf("Erro: Arquivo '%s' não encontrado\n", filename)
 		os.Exit(1)
@@ -118,7 +134,15 @@ func runFile(filename string) {
 		}
 	}
 
-	eval := evaluator.New()
+	if errors := resolver.Resolve(program); len(errors) != 0 {
+		fmt.Println("Erros de resolução encontrados:")
+		for _, err := range errors {
+			fmt.Printf("  %s:%s\n", filename, err)
+		}
+		os.Exit(1)
+	}
+
+	eval := evaluator.NewWithOptions(options)
 	result := eval.Eval(program)
 
 	if result != nil && result.Type() == "ERROR" {
@@ -162,6 +186,43 @@ func formatFile(filename string) {
 	fmt.Printf("Arquivo '%s' formatado com sucesso!\n", filename)
 }
 
+func checkFile(filename string) {
+	if !fileExists(filename) {
+		fmt.Printf("Erro: Arquivo '%s' não encontrado\n", filename)
+		os.Exit(1)
+	}
+
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		fmt.Printf("Erro ao ler arquivo: %v\n", err)
+		os.Exit(1)
+	}
+
+	l := lexer.New(string(content))
+	p := parser.New(l)
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		fmt.Println("Erros de sintaxe encontrados:")
+		for _, err := range p.Errors() {
+			fmt.Printf("  %s\n", err)
+		}
+		os.Exit(1)
+	}
+
+	diagnostics := checker.Check(program)
+	if len(diagnostics) == 0 {
+		fmt.Printf("Nenhum problema de tipos encontrado em '%s'.\n", filename)
+		return
+	}
+
+	for _, diagnostic := range diagnostics {
+		fmt.Printf("%s:%s\n", filename, diagnostic)
+	}
+	fmt.Printf("%d problema(s) de tipos encontrado(s).\n", len(diagnostics))
+	os.Exit(1)
+}
+
 func installLibrary(libraryName string) {
 	libManager := library.NewLibraryManager()
 
@@ -190,6 +251,15 @@ func listLibraries() {
 	}
 }
 
+func hasFlag(flag string) bool {
+	for _, arg := range os.Args[2:] {
+		if arg == flag {
+			return true
+		}
+	}
+	return false
+}
+
 func fileExists(filename string) bool {
 	_, err := os.Stat(filename)
 	return !os.IsNotExist(err)
@@ -2,6 +2,7 @@ package ast
 
 import (
 	"bytes"
+	"math/big"
 	"sovylang/internal/token"
 	"strings"
 )
@@ -23,8 +24,40 @@ type Expression interface {
 
 type Program struct {
 	Statements []Statement
+	// Scope é preenchido pelo resolvedor com os nomes do escopo global.
+	Scope *Scope
 }
 
+// Scope é a disposição de um quadro de variáveis calculada pelo resolvedor:
+// cada nome declarado no escopo ocupa uma posição fixa.
+type Scope struct {
+	Names []string
+	Slots map[string]int
+}
+
+func NewScope(names ...string) *Scope {
+	s := &Scope{Slots: map[string]int{}}
+	for _, name := range names {
+		s.Declare(name)
+	}
+	return s
+}
+
+// Declare reserva uma posição para name e a devolve. Declarar de novo um
+// nome devolve a posição já reservada.
+func (s *Scope) Declare(name string) int {
+	if slot, ok := s.Slots[name]; ok {
+		return slot
+	}
+	s.Slots[name] = len(s.Names)
+	s.Names = append(s.Names, name)
+	return len(s.Names) - 1
+}
+
+// MethodScope é o quadro criado a cada chamada de método, entre o ambiente
+// da classe e o da função.
+var MethodScope = NewScope("este", "super")
+
 func (p *Program) TokenLiteral() string {
 	if len(p.Statements) > 0 {
 		return p.Statements[0].TokenLiteral()
@@ -40,18 +73,26 @@ func (p *Program) String() string {
 	return out.String()
 }
 
+// VarStatement declara uma variável. Constant marca "constante NOME = valor",
+// cuja ligação não pode ser alterada; nesse caso o tipo é opcional.
 type VarStatement struct {
-	Token token.Token
-	Type  string
-	Name  *Identifier
-	Value Expression
+	Token    token.Token
+	Type     string
+	Name     *Identifier
+	Value    Expression
+	Constant bool
 }
 
 func (vs *VarStatement) statementNode()       {}
 func (vs *VarStatement) TokenLiteral() string { return vs.Token.Literal }
 func (vs *VarStatement) String() string {
 	var out bytes.Buffer
-	out.WriteString(vs.Type + " ")
+	if vs.Constant {
+		out.WriteString("constante ")
+	}
+	if vs.Type != "" {
+		out.WriteString(vs.Type + " ")
+	}
 	out.WriteString(vs.Name.String())
 	out.WriteString(" = ")
 	if vs.Value != nil {
@@ -60,6 +101,56 @@ func (vs *VarStatement) String() string {
 	return out.String()
 }
 
+// DestructureStatement declara várias variáveis a partir de uma lista ou
+// tupla (`lista [a, b] = f()`) ou dos campos de um mapa, registro ou objeto
+// (`mapa {nome, idade} = pessoa`).
+type DestructureStatement struct {
+	Token token.Token
+	Names []*Identifier
+	Value Expression
+}
+
+func (ds *DestructureStatement) statementNode()       {}
+func (ds *DestructureStatement) TokenLiteral() string { return ds.Token.Literal }
+func (ds *DestructureStatement) IsMap() bool          { return ds.Token.Type == token.MAPA }
+func (ds *DestructureStatement) String() string {
+	var out bytes.Buffer
+	names := []string{}
+	for _, name := range ds.Names {
+		names = append(names, name.String())
+	}
+	open, close := "[", "]"
+	if ds.IsMap() {
+		open, close = "{", "}"
+	}
+	out.WriteString(ds.Token.Literal + " " + open + strings.Join(names, ", ") + close + " = ")
+	if ds.Value != nil {
+		out.WriteString(ds.Value.String())
+	}
+	return out.String()
+}
+
+// AssignStatement altera uma variável existente (`x = 1`) ou um elemento de
+// lista ou mapa (`lista[0] = 1`, `mapa["chave"] = 1`). Com vários alvos
+// (`a, b = b, a`) Target é uma TupleLiteral.
+type AssignStatement struct {
+	Token  token.Token
+	Target Expression
+	Value  Expression
+}
+
+func (as *AssignStatement) statementNode()       {}
+func (as *AssignStatement) TokenLiteral() string { return as.Token.Literal }
+func (as *AssignStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString(as.Target.String())
+	out.WriteString(" = ")
+	if as.Value != nil {
+		out.WriteString(as.Value.String())
+	}
+	return out.String()
+}
+
 type ReturnStatement struct {
 	Token       token.Token
 	ReturnValue Expression
@@ -90,9 +181,12 @@ func (es *ExpressionStatement) String() string {
 	return ""
 }
 
+// BlockStatement é o corpo de uma função, de um ramo de se, de uma volta de
+// para ou de um caso. Scope é a disposição do quadro criado para ele.
 type BlockStatement struct {
 	Token      token.Token
 	Statements []Statement
+	Scope      *Scope
 }
 
 func (bs *BlockStatement) statementNode()       {}
@@ -129,6 +223,112 @@ func (fs *ForStatement) String() string {
 	return out.String()
 }
 
+type ForEachStatement struct {
+	Token    token.Token
+	Variable *Identifier
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+func (fs *ForEachStatement) statementNode()       {}
+func (fs *ForEachStatement) TokenLiteral() string { return fs.Token.Literal }
+func (fs *ForEachStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString("para cada ")
+	out.WriteString(fs.Variable.String())
+	out.WriteString(" em ")
+	out.WriteString(fs.Iterable.String())
+	out.WriteString(fs.Body.String())
+	out.WriteString("fim")
+	return out.String()
+}
+
+type RecordField struct {
+	Token   token.Token
+	Name    *Identifier
+	Type    string
+	Default Expression
+}
+
+func (rf *RecordField) String() string {
+	var out bytes.Buffer
+	if rf.Type != "" {
+		out.WriteString(rf.Type + " ")
+	}
+	out.WriteString(rf.Name.String())
+	if rf.Default != nil {
+		out.WriteString(" = ")
+		out.WriteString(rf.Default.String())
+	}
+	return out.String()
+}
+
+type RecordStatement struct {
+	Token  token.Token
+	Name   *Identifier
+	Fields []*RecordField
+}
+
+func (rs *RecordStatement) statementNode()       {}
+func (rs *RecordStatement) TokenLiteral() string { return rs.Token.Literal }
+func (rs *RecordStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString("registro ")
+	out.WriteString(rs.Name.String())
+	for _, field := range rs.Fields {
+		out.WriteString(" ")
+		out.WriteString(field.String())
+	}
+	out.WriteString(" fim")
+	return out.String()
+}
+
+type ClassStatement struct {
+	Token      token.Token
+	Name       *Identifier
+	Superclass *Identifier
+	Fields     []*RecordField
+	Methods    []*FunctionLiteral
+}
+
+func (cs *ClassStatement) statementNode()       {}
+func (cs *ClassStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ClassStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString("classe ")
+	out.WriteString(cs.Name.String())
+	if cs.Superclass != nil {
+		out.WriteString(" herda ")
+		out.WriteString(cs.Superclass.String())
+	}
+	for _, field := range cs.Fields {
+		out.WriteString(" ")
+		out.WriteString(field.String())
+	}
+	for _, method := range cs.Methods {
+		out.WriteString(" ")
+		out.WriteString(method.String())
+	}
+	out.WriteString(" fim")
+	return out.String()
+}
+
+type EnumStatement struct {
+	Token   token.Token
+	Name    *Identifier
+	Members []*Identifier
+}
+
+func (es *EnumStatement) statementNode()       {}
+func (es *EnumStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *EnumStatement) String() string {
+	members := []string{}
+	for _, m := range es.Members {
+		members = append(members, m.String())
+	}
+	return "enumeracao " + es.Name.String() + " " + strings.Join(members, ", ") + " fim"
+}
+
 type IncludeStatement struct {
 	Token   token.Token
 	Library *Identifier
@@ -144,9 +344,16 @@ func (is *IncludeStatement) String() string {
 	return out.String()
 }
 
+// Identifier é um nome. Quando Resolved, o valor está no quadro Depth níveis
+// acima do atual, na posição Slot; Builtin marca um nome que só pode ser uma
+// função built-in.
 type Identifier struct {
-	Token token.Token
-	Value string
+	Token    token.Token
+	Value    string
+	Resolved bool
+	Builtin  bool
+	Depth    int
+	Slot     int
 }
 
 func (i *Identifier) expressionNode()      {}
@@ -156,6 +363,7 @@ func (i *Identifier) String() string       { return i.Value }
 type IntegerLiteral struct {
 	Token token.Token
 	Value int64
+	Big   *big.Int
 }
 
 func (il *IntegerLiteral) expressionNode()      {}
@@ -189,6 +397,14 @@ func (b *Boolean) expressionNode()      {}
 func (b *Boolean) TokenLiteral() string { return b.Token.Literal }
 func (b *Boolean) String() string       { return b.Token.Literal }
 
+type NullLiteral struct {
+	Token token.Token
+}
+
+func (nl *NullLiteral) expressionNode()      {}
+func (nl *NullLiteral) TokenLiteral() string { return nl.Token.Literal }
+func (nl *NullLiteral) String() string       { return nl.Token.Literal }
+
 type PrefixExpression struct {
 	Token    token.Token
 	Operator string
@@ -225,6 +441,27 @@ func (ie *InfixExpression) String() string {
 	return out.String()
 }
 
+// LogicalExpression representa `e`, `ou` e `??`, que avaliam o lado direito
+// apenas quando o esquerdo não decide o resultado.
+type LogicalExpression struct {
+	Token    token.Token
+	Left     Expression
+	Operator string
+	Right    Expression
+}
+
+func (le *LogicalExpression) expressionNode()      {}
+func (le *LogicalExpression) TokenLiteral() string { return le.Token.Literal }
+func (le *LogicalExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("(")
+	out.WriteString(le.Left.String())
+	out.WriteString(" " + le.Operator + " ")
+	out.WriteString(le.Right.String())
+	out.WriteString(")")
+	return out.String()
+}
+
 type IfExpression struct {
 	Token       token.Token
 	Condition   Expression
@@ -248,11 +485,108 @@ func (ie *IfExpression) String() string {
 	return out.String()
 }
 
+// RangePattern só aparece em padrões de caso: "caso 1 até 10" (inclusivo).
+type RangePattern struct {
+	Token token.Token
+	Low   Expression
+	High  Expression
+}
+
+func (rp *RangePattern) expressionNode()      {}
+func (rp *RangePattern) TokenLiteral() string { return rp.Token.Literal }
+func (rp *RangePattern) String() string {
+	return rp.Low.String() + " até " + rp.High.String()
+}
+
+type SwitchCase struct {
+	Token    token.Token
+	Patterns []Expression
+	Guard    Expression
+	Body     *BlockStatement
+}
+
+func (sc *SwitchCase) String() string {
+	var out bytes.Buffer
+	patterns := []string{}
+	for _, p := range sc.Patterns {
+		patterns = append(patterns, p.String())
+	}
+	out.WriteString("caso ")
+	out.WriteString(strings.Join(patterns, ", "))
+	if sc.Guard != nil {
+		out.WriteString(" se ")
+		out.WriteString(sc.Guard.String())
+	}
+	out.WriteString(" ")
+	out.WriteString(sc.Body.String())
+	return out.String()
+}
+
+type SwitchExpression struct {
+	Token   token.Token
+	Subject Expression
+	Cases   []*SwitchCase
+	Default *BlockStatement
+}
+
+func (se *SwitchExpression) expressionNode()      {}
+func (se *SwitchExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SwitchExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("escolha ")
+	out.WriteString(se.Subject.String())
+	for _, c := range se.Cases {
+		out.WriteString(" ")
+		out.WriteString(c.String())
+	}
+	if se.Default != nil {
+		out.WriteString(" padrão ")
+		out.WriteString(se.Default.String())
+	}
+	out.WriteString(" fim")
+	return out.String()
+}
+
+// Parameter é um parâmetro de função: obrigatório (`a`), com valor padrão
+// (`b = 10`) ou variádico (`...resto`), que recebe os argumentos extras.
+// Type é opcional (`numero a`); em um variádico vale para cada argumento.
+type Parameter struct {
+	Token    token.Token
+	Type     string
+	Name     *Identifier
+	Default  Expression
+	Variadic bool
+}
+
+func (p *Parameter) String() string {
+	var out bytes.Buffer
+	if p.Type != "" {
+		out.WriteString(p.Type + " ")
+	}
+	if p.Variadic {
+		out.WriteString("...")
+	}
+	out.WriteString(p.Name.String())
+	if p.Default != nil {
+		out.WriteString(" = " + p.Default.String())
+	}
+	return out.String()
+}
+
 type FunctionLiteral struct {
 	Token      token.Token
 	Name       *Identifier
-	Parameters []*Identifier
+	Parameters []*Parameter
+	ReturnType string
 	Body       *BlockStatement
+	// Concise marca a forma curta funcao(x) = expressão; o corpo é um único
+	// retorne com a expressão.
+	Concise bool
+}
+
+// ConciseValue retorna a expressão de uma função na forma curta.
+func (fl *FunctionLiteral) ConciseValue() Expression {
+	return fl.Body.Statements[0].(*ReturnStatement).ReturnValue
 }
 
 func (fl *FunctionLiteral) expressionNode()      {}
@@ -270,6 +604,13 @@ func (fl *FunctionLiteral) String() string {
 	out.WriteString("(")
 	out.WriteString(strings.Join(params, ", "))
 	out.WriteString(")")
+	if fl.ReturnType != "" {
+		out.WriteString(" -> " + fl.ReturnType)
+	}
+	if fl.Concise {
+		out.WriteString(" = " + fl.ConciseValue().String())
+		return out.String()
+	}
 	out.WriteString(fl.Body.String())
 	out.WriteString("fim")
 	return out.String()
@@ -296,6 +637,30 @@ func (ce *CallExpression) String() string {
 	return out.String()
 }
 
+type NamedArgument struct {
+	Token token.Token
+	Name  *Identifier
+	Value Expression
+}
+
+func (na *NamedArgument) expressionNode()      {}
+func (na *NamedArgument) TokenLiteral() string { return na.Token.Literal }
+func (na *NamedArgument) String() string {
+	return na.Name.String() + ": " + na.Value.String()
+}
+
+type MemberExpression struct {
+	Token    token.Token
+	Object   Expression
+	Property *Identifier
+}
+
+func (me *MemberExpression) expressionNode()      {}
+func (me *MemberExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MemberExpression) String() string {
+	return "(" + me.Object.String() + "." + me.Property.String() + ")"
+}
+
 type ArrayLiteral struct {
 	Token    token.Token
 	Elements []Expression
@@ -315,9 +680,55 @@ func (al *ArrayLiteral) String() string {
 	return out.String()
 }
 
+type TupleLiteral struct {
+	Token    token.Token
+	Elements []Expression
+}
+
+func (tl *TupleLiteral) expressionNode()      {}
+func (tl *TupleLiteral) TokenLiteral() string { return tl.Token.Literal }
+func (tl *TupleLiteral) String() string {
+	var out bytes.Buffer
+	elements := []string{}
+	for _, e := range tl.Elements {
+		elements = append(elements, e.String())
+	}
+	out.WriteString("(")
+	out.WriteString(strings.Join(elements, ", "))
+	if len(elements) == 1 {
+		out.WriteString(",")
+	}
+	out.WriteString(")")
+	return out.String()
+}
+
+type SetLiteral struct {
+	Token    token.Token
+	Elements []Expression
+}
+
+func (sl *SetLiteral) expressionNode()      {}
+func (sl *SetLiteral) TokenLiteral() string { return sl.Token.Literal }
+func (sl *SetLiteral) String() string {
+	var out bytes.Buffer
+	elements := []string{}
+	for _, e := range sl.Elements {
+		elements = append(elements, e.String())
+	}
+	out.WriteString("conjunto{")
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString("}")
+	return out.String()
+}
+
+type HashLiteralPair struct {
+	Key   Expression
+	Value Expression
+}
+
 type HashLiteral struct {
 	Token token.Token
-	Pairs map[Expression]Expression
+	Pairs []HashLiteralPair
 }
 
 func (hl *HashLiteral) expressionNode()      {}
@@ -325,8 +736,8 @@ func (hl *HashLiteral) TokenLiteral() string { return hl.Token.Literal }
 func (hl *HashLiteral) String() string {
 	var out bytes.Buffer
 	pairs := []string{}
-	for key, value := range hl.Pairs {
-		pairs = append(pairs, key.String()+": "+value.String())
+	for _, pair := range hl.Pairs {
+		pairs = append(pairs, pair.Key.String()+": "+pair.Value.String())
 	}
 	out.WriteString("{")
 	out.WriteString(strings.Join(pairs, ", "))
@@ -335,9 +746,10 @@ func (hl *HashLiteral) String() string {
 }
 
 type IndexExpression struct {
-	Token token.Token
-	Left  Expression
-	Index Expression
+	Token    token.Token
+	Left     Expression
+	Index    Expression
+	Optional bool
 }
 
 func (ie *IndexExpression) expressionNode()      {}
@@ -346,8 +758,47 @@ func (ie *IndexExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString("(")
 	out.WriteString(ie.Left.String())
+	if ie.Optional {
+		out.WriteString("?")
+	}
 	out.WriteString("[")
 	out.WriteString(ie.Index.String())
 	out.WriteString("])")
 	return out.String()
 }
+
+// SliceExpression representa `lista[inicio:fim:passo]`; qualquer uma das três
+// partes pode ser omitida.
+type SliceExpression struct {
+	Token    token.Token
+	Left     Expression
+	Start    Expression
+	End      Expression
+	Step     Expression
+	Optional bool
+}
+
+func (se *SliceExpression) expressionNode()      {}
+func (se *SliceExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SliceExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("(")
+	out.WriteString(se.Left.String())
+	if se.Optional {
+		out.WriteString("?")
+	}
+	out.WriteString("[")
+	if se.Start != nil {
+		out.WriteString(se.Start.String())
+	}
+	out.WriteString(":")
+	if se.End != nil {
+		out.WriteString(se.End.String())
+	}
+	if se.Step != nil {
+		out.WriteString(":")
+		out.WriteString(se.Step.String())
+	}
+	out.WriteString("])")
+	return out.String()
+}
@@ -0,0 +1,1107 @@
+// Package checker implementa a verificação estática de tipos usada por
+// "sovy tipos". A verificação é gradual: valores de tipo desconhecido são
+// aceitos em qualquer lugar, então código sem anotações não gera avisos.
+package checker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"sovylang/internal/ast"
+	"sovylang/internal/token"
+)
+
+const (
+	typeUnknown = ""
+	typeNumber  = "numero"
+	typeText    = "texto"
+	typeBool    = "booleano"
+	typeList    = "lista"
+	typeMap     = "mapa"
+	typeSet     = "conjunto"
+	typeTuple   = "tupla"
+	typeFunc    = "funcao"
+	typeNull    = "nulo"
+	typeEnum    = "enumeracao"
+)
+
+// Diagnostic é um problema de tipos encontrado antes da execução.
+type Diagnostic struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%d:%d: %s", d.Line, d.Column, d.Message)
+}
+
+type fieldInfo struct {
+	Name       string
+	Type       string
+	HasDefault bool
+}
+
+// typeInfo descreve um registro ou uma classe declarados no programa.
+type typeInfo struct {
+	Name    string
+	Class   bool
+	Super   *typeInfo
+	Fields  []fieldInfo
+	Methods map[string]*signature
+}
+
+func (t *typeInfo) field(name string) (fieldInfo, bool) {
+	for _, field := range t.Fields {
+		if field.Name == name {
+			return field, true
+		}
+	}
+	return fieldInfo{}, false
+}
+
+func (t *typeInfo) method(name string) (*signature, bool) {
+	for info := t; info != nil; info = info.Super {
+		if sig, ok := info.Methods[name]; ok {
+			return sig, true
+		}
+	}
+	return nil, false
+}
+
+type enumInfo struct {
+	Name    string
+	Members []string
+}
+
+func (e *enumInfo) has(name string) bool {
+	for _, member := range e.Members {
+		if member == name {
+			return true
+		}
+	}
+	return false
+}
+
+// symbol guarda o que se sabe sobre um nome. Declared indica que o tipo veio
+// de uma anotação e deve ser respeitado nas atribuições.
+type symbol struct {
+	Type     string
+	Declared bool
+	Constant bool
+	Sig      *signature
+	Info     *typeInfo
+	Enum     *enumInfo
+}
+
+// scope espelha object.Environment: block marca escopos de se, para e caso,
+// que pertencem à mesma função do escopo externo.
+type scope struct {
+	symbols map[string]*symbol
+	outer   *scope
+	block   bool
+}
+
+func newScope(outer *scope) *scope {
+	return &scope{symbols: map[string]*symbol{}, outer: outer}
+}
+
+func newBlockScope(outer *scope) *scope {
+	s := newScope(outer)
+	s.block = true
+	return s
+}
+
+func (s *scope) lookup(name string) (*symbol, bool) {
+	for current := s; current != nil; current = current.outer {
+		if sym, ok := current.symbols[name]; ok {
+			return sym, true
+		}
+	}
+	return nil, false
+}
+
+// declaredVariable procura name entre as variáveis do escopo atual e dos
+// blocos externos da mesma função.
+func (s *scope) declaredVariable(name string) (*symbol, bool, bool) {
+	for current := s; current != nil; current = current.outer {
+		if sym, ok := current.symbols[name]; ok && sym.Sig == nil && sym.Info == nil && sym.Enum == nil {
+			return sym, current == s, true
+		}
+		if !current.block {
+			break
+		}
+	}
+	return nil, false, false
+}
+
+func (s *scope) define(name string, sym *symbol) {
+	s.symbols[name] = sym
+}
+
+type checker struct {
+	diagnostics []Diagnostic
+	types       map[string]*typeInfo
+	enums       map[string]*enumInfo
+	function    *signature
+}
+
+// Check analisa o programa e retorna os diagnósticos ordenados por posição.
+func Check(program *ast.Program) []Diagnostic {
+	c := &checker{types: map[string]*typeInfo{}, enums: map[string]*enumInfo{}}
+
+	globals := newScope(nil)
+	for name, sig := range builtinSignatures {
+		globals.define(name, &symbol{Type: typeFunc, Sig: sig})
+	}
+
+	c.declare(program.Statements, globals)
+	for _, statement := range program.Statements {
+		c.checkStatement(statement, globals)
+	}
+
+	sort.SliceStable(c.diagnostics, func(i, j int) bool {
+		a, b := c.diagnostics[i], c.diagnostics[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return c.diagnostics
+}
+
+func (c *checker) report(tok token.Token, format string, args ...interface{}) {
+	c.diagnostics = append(c.diagnostics, Diagnostic{Line: tok.Line, Column: tok.Column, Message: fmt.Sprintf(format, args...)})
+}
+
+// declare registra antecipadamente funções, registros, classes e enumerações
+// do nível superior, para que corpos de funções possam usá-los antes da
+// declaração.
+func (c *checker) declare(statements []ast.Statement, s *scope) {
+	for _, statement := range statements {
+		switch statement := statement.(type) {
+		case *ast.ExpressionStatement:
+			if fn, ok := statement.Expression.(*ast.FunctionLiteral); ok && fn.Name != nil {
+				s.define(fn.Name.Value, &symbol{Type: typeFunc, Sig: signatureOf(fn, "")})
+			}
+		case *ast.RecordStatement:
+			c.declareRecord(statement, s)
+		case *ast.ClassStatement:
+			c.declareClass(statement, s, false)
+		case *ast.EnumStatement:
+			c.declareEnum(statement, s)
+		}
+	}
+}
+
+func (c *checker) declareRecord(node *ast.RecordStatement, s *scope) *typeInfo {
+	info := &typeInfo{Name: node.Name.Value}
+	for _, field := range node.Fields {
+		info.Fields = append(info.Fields, fieldInfo{Name: field.Name.Value, Type: normalizeType(field.Type), HasDefault: field.Default != nil})
+	}
+	c.types[info.Name] = info
+	s.define(info.Name, &symbol{Info: info})
+	return info
+}
+
+func (c *checker) declareClass(node *ast.ClassStatement, s *scope, report bool) *typeInfo {
+	info := &typeInfo{Name: node.Name.Value, Class: true, Methods: map[string]*signature{}}
+
+	if node.Superclass != nil {
+		sym, ok := s.lookup(node.Superclass.Value)
+		switch {
+		case ok && sym.Info != nil && sym.Info.Class:
+			info.Super = sym.Info
+			info.Fields = append(info.Fields, sym.Info.Fields...)
+		case report && ok:
+			c.report(node.Superclass.Token, "%s não é uma classe", node.Superclass.Value)
+		case report:
+			c.report(node.Superclass.Token, "classe base desconhecida: %s", node.Superclass.Value)
+		}
+	}
+
+	for _, field := range node.Fields {
+		declared := fieldInfo{Name: field.Name.Value, Type: normalizeType(field.Type), HasDefault: field.Default != nil}
+		if existing, ok := info.field(declared.Name); ok {
+			// subclasses podem trocar o valor padrão de um campo herdado
+			declared.Type = existing.Type
+			for i := range info.Fields {
+				if info.Fields[i].Name == declared.Name {
+					info.Fields[i] = declared
+				}
+			}
+			continue
+		}
+		info.Fields = append(info.Fields, declared)
+	}
+
+	for _, method := range node.Methods {
+		info.Methods[method.Name.Value] = signatureOf(method, info.Name)
+	}
+
+	c.types[info.Name] = info
+	s.define(info.Name, &symbol{Info: info})
+	return info
+}
+
+func (c *checker) declareEnum(node *ast.EnumStatement, s *scope) {
+	enum := &enumInfo{Name: node.Name.Value}
+	for _, member := range node.Members {
+		enum.Members = append(enum.Members, member.Value)
+	}
+	c.enums[enum.Name] = enum
+	s.define(enum.Name, &symbol{Type: typeEnum, Enum: enum})
+}
+
+func signatureOf(fn *ast.FunctionLiteral, owner string) *signature {
+	sig := &signature{Name: "função anônima", Result: normalizeType(fn.ReturnType), ResultFromArg: -1}
+	if fn.Name != nil {
+		sig.Name = fn.Name.Value
+		if owner != "" {
+			sig.Name = owner + "." + fn.Name.Value
+		}
+	}
+	for _, p := range fn.Parameters {
+		declared := param{Name: p.Name.Value, Optional: p.Default != nil, Variadic: p.Variadic}
+		if p.Type != "" {
+			declared.Types = []string{normalizeType(p.Type)}
+		}
+		sig.Params = append(sig.Params, declared)
+	}
+	return sig
+}
+
+func normalizeType(name string) string {
+	if name == "função" {
+		return typeFunc
+	}
+	return name
+}
+
+func isBuiltinType(name string) bool {
+	switch name {
+	case typeNumber, typeText, typeBool, typeList, typeMap, typeSet, typeTuple, typeFunc:
+		return true
+	}
+	return false
+}
+
+// checkTypeName avisa sobre anotações que não correspondem a nenhum tipo.
+func (c *checker) checkTypeName(tok token.Token, name string) {
+	if name == "" || isBuiltinType(name) {
+		return
+	}
+	if _, ok := c.types[name]; ok {
+		return
+	}
+	if _, ok := c.enums[name]; ok {
+		return
+	}
+	c.report(tok, "tipo desconhecido: %s", name)
+}
+
+// compatible diz se um valor do tipo actual pode ocupar um lugar do tipo
+// expected. Tipos desconhecidos e nulo são sempre aceitos.
+func (c *checker) compatible(expected, actual string) bool {
+	if expected == typeUnknown || actual == typeUnknown || actual == typeNull || expected == actual {
+		return true
+	}
+	if info, ok := c.types[actual]; ok {
+		for super := info.Super; super != nil; super = super.Super {
+			if super.Name == expected {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *checker) acceptsAny(expected []string, actual string) bool {
+	if len(expected) == 0 {
+		return true
+	}
+	for _, typ := range expected {
+		if c.compatible(typ, actual) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *checker) checkStatement(node ast.Statement, s *scope) {
+	switch node := node.(type) {
+	case *ast.ExpressionStatement:
+		c.infer(node.Expression, s)
+
+	case *ast.VarStatement:
+		declared := normalizeType(node.Type)
+		c.checkTypeName(node.Token, declared)
+		actual := c.infer(node.Value, s)
+		c.checkRedeclaration(node.Name, s)
+		if !c.compatible(declared, actual) {
+			c.report(node.Name.Token, "%s declarado como %s, mas recebe %s", node.Name.Value, declared, actual)
+			// evita avisos em cascata nos usos seguintes
+			s.define(node.Name.Value, &symbol{})
+			return
+		}
+		if node.Constant {
+			if declared == typeUnknown {
+				declared = actual
+			}
+			s.define(node.Name.Value, &symbol{Type: declared, Declared: true, Constant: true})
+			return
+		}
+		s.define(node.Name.Value, &symbol{Type: declared, Declared: true})
+
+	case *ast.AssignStatement:
+		c.checkAssignment(node.Target, c.infer(node.Value, s), node.Value, s)
+
+	case *ast.DestructureStatement:
+		c.infer(node.Value, s)
+		for _, name := range node.Names {
+			c.checkRedeclaration(name, s)
+			s.define(name.Value, &symbol{})
+		}
+
+	case *ast.ReturnStatement:
+		if node.ReturnValue == nil {
+			return
+		}
+		actual := c.infer(node.ReturnValue, s)
+		if c.function != nil && !c.compatible(c.function.Result, actual) {
+			c.report(node.Token, "%s: deve retornar %s, retornou %s", c.function.Name, c.function.Result, actual)
+		}
+
+	case *ast.ForStatement:
+		for _, bound := range []ast.Expression{node.Start, node.End} {
+			if typ := c.infer(bound, s); !c.compatible(typeNumber, typ) {
+				c.report(startToken(bound), "limites de para devem ser numero, recebido %s", typ)
+			}
+		}
+		c.checkRedeclaration(node.Variable, s)
+		loop := newBlockScope(s)
+		loop.define(node.Variable.Value, &symbol{Type: typeNumber})
+		c.checkBlock(node.Body, loop)
+
+	case *ast.ForEachStatement:
+		element := c.elementType(node.Iterable, s)
+		c.checkRedeclaration(node.Variable, s)
+		loop := newBlockScope(s)
+		loop.define(node.Variable.Value, &symbol{Type: element})
+		c.checkBlock(node.Body, loop)
+
+	case *ast.RecordStatement:
+		info := c.declareRecord(node, s)
+		c.checkFields(info.Name, node.Fields, s)
+
+	case *ast.ClassStatement:
+		info := c.declareClass(node, s, true)
+		c.checkFields(info.Name, node.Fields, s)
+		for _, method := range node.Methods {
+			methodScope := newScope(s)
+			methodScope.define("este", &symbol{Type: info.Name})
+			methodScope.define("super", &symbol{})
+			c.checkFunctionBody(method, info.Methods[method.Name.Value], methodScope)
+		}
+
+	case *ast.EnumStatement:
+		c.declareEnum(node, s)
+
+	case *ast.IncludeStatement:
+		for name, sig := range librarySignatures[node.Library.Value] {
+			s.define(name, &symbol{Type: typeFunc, Sig: sig, Constant: true})
+		}
+	}
+}
+
+// checkRedeclaration segue a regra do interpretador: uma variável por nome em
+// cada escopo, e blocos não redeclaram nomes visíveis da mesma função.
+func (c *checker) checkRedeclaration(name *ast.Identifier, s *scope) {
+	sym, here, ok := s.declaredVariable(name.Value)
+	switch {
+	case !ok:
+	case here && sym.Constant:
+		c.report(name.Token, "constante %s não pode ser redeclarada", name.Value)
+	case here:
+		c.report(name.Token, "variável %s já declarada neste escopo", name.Value)
+	default:
+		c.report(name.Token, "variável %s já declarada em um escopo externo; para alterar o valor use %s = ...", name.Value, name.Value)
+	}
+}
+
+func (c *checker) checkBlock(block *ast.BlockStatement, s *scope) {
+	if block == nil {
+		return
+	}
+	for _, statement := range block.Statements {
+		c.checkStatement(statement, s)
+	}
+}
+
+func (c *checker) checkFields(owner string, fields []*ast.RecordField, s *scope) {
+	for _, field := range fields {
+		typ := normalizeType(field.Type)
+		c.checkTypeName(field.Token, typ)
+		if field.Default == nil {
+			continue
+		}
+		if actual := c.infer(field.Default, s); !c.compatible(typ, actual) {
+			c.report(startToken(field.Default), "campo %s de %s deve ser %s, recebido %s", field.Name.Value, owner, typ, actual)
+		}
+	}
+}
+
+func (c *checker) checkAssignment(target ast.Expression, actual string, value ast.Expression, s *scope) {
+	switch target := target.(type) {
+	case *ast.Identifier:
+		sym, ok := s.lookup(target.Value)
+		if !ok {
+			return
+		}
+		if sym.Constant {
+			c.report(target.Token, "não é possível alterar a constante %s", target.Value)
+			return
+		}
+		if sym.Declared && !c.compatible(sym.Type, actual) {
+			c.report(target.Token, "%s é %s, mas recebe %s", target.Value, sym.Type, actual)
+		}
+
+	case *ast.MemberExpression:
+		info, field, ok := c.memberField(target, s)
+		if ok && !c.compatible(field.Type, actual) {
+			c.report(target.Property.Token, "campo %s de %s deve ser %s, recebido %s", field.Name, info.Name, field.Type, actual)
+		}
+
+	case *ast.IndexExpression:
+		c.infer(target, s)
+
+	case *ast.TupleLiteral:
+		values, ok := value.(*ast.TupleLiteral)
+		for i, element := range target.Elements {
+			if ok && len(values.Elements) == len(target.Elements) {
+				c.checkAssignment(element, c.infer(values.Elements[i], s), values.Elements[i], s)
+			} else {
+				c.checkAssignment(element, typeUnknown, nil, s)
+			}
+		}
+	}
+}
+
+// memberField resolve o campo de um registro ou objeto acessado por
+// obj.campo, quando o tipo do objeto é conhecido.
+func (c *checker) memberField(node *ast.MemberExpression, s *scope) (*typeInfo, fieldInfo, bool) {
+	info, ok := c.types[c.infer(node.Object, s)]
+	if !ok {
+		return nil, fieldInfo{}, false
+	}
+	field, ok := info.field(node.Property.Value)
+	if !ok {
+		c.reportMissingMember(info, node.Property)
+	}
+	return info, field, ok
+}
+
+func (c *checker) reportMissingMember(info *typeInfo, property *ast.Identifier) {
+	if info.Class {
+		c.report(property.Token, "classe %s não tem o campo %s", info.Name, property.Value)
+		return
+	}
+	c.report(property.Token, "registro %s não tem o campo %s", info.Name, property.Value)
+}
+
+func (c *checker) checkFunctionBody(fn *ast.FunctionLiteral, sig *signature, s *scope) {
+	fnScope := newScope(s)
+	for _, p := range fn.Parameters {
+		typ := normalizeType(p.Type)
+		c.checkTypeName(p.Token, typ)
+		if p.Default != nil {
+			if actual := c.infer(p.Default, fnScope); !c.compatible(typ, actual) {
+				c.report(startToken(p.Default), "%s: parâmetro %s deve ser %s, recebido %s", sig.Name, p.Name.Value, typ, actual)
+			}
+		}
+		if p.Variadic {
+			fnScope.define(p.Name.Value, &symbol{Type: typeList})
+			continue
+		}
+		fnScope.define(p.Name.Value, &symbol{Type: typ, Declared: typ != typeUnknown})
+	}
+	c.checkTypeName(fn.Token, sig.Result)
+
+	outer := c.function
+	c.function = sig
+	c.checkBlock(fn.Body, fnScope)
+	c.function = outer
+}
+
+func (c *checker) infer(node ast.Expression, s *scope) string {
+	switch node := node.(type) {
+	case *ast.IntegerLiteral, *ast.FloatLiteral:
+		return typeNumber
+	case *ast.StringLiteral:
+		return typeText
+	case *ast.Boolean:
+		return typeBool
+	case *ast.NullLiteral:
+		return typeNull
+
+	case *ast.ArrayLiteral:
+		c.inferAll(node.Elements, s)
+		return typeList
+	case *ast.TupleLiteral:
+		c.inferAll(node.Elements, s)
+		return typeTuple
+	case *ast.SetLiteral:
+		c.inferAll(node.Elements, s)
+		return typeSet
+	case *ast.HashLiteral:
+		for _, pair := range node.Pairs {
+			c.infer(pair.Key, s)
+			c.infer(pair.Value, s)
+		}
+		return typeMap
+
+	case *ast.Identifier:
+		if sym, ok := s.lookup(node.Value); ok {
+			return sym.Type
+		}
+		return typeUnknown
+
+	case *ast.PrefixExpression:
+		return c.inferPrefix(node, s)
+	case *ast.InfixExpression:
+		return c.inferInfix(node, s)
+
+	case *ast.LogicalExpression:
+		left := c.infer(node.Left, s)
+		right := c.infer(node.Right, s)
+		if node.Operator != "??" {
+			return typeBool
+		}
+		if left == right {
+			return left
+		}
+		if left == typeNull {
+			return right
+		}
+		return typeUnknown
+
+	case *ast.IfExpression:
+		c.infer(node.Condition, s)
+		c.checkBlock(node.Consequence, newBlockScope(s))
+		c.checkBlock(node.Alternative, newBlockScope(s))
+		return typeUnknown
+
+	case *ast.SwitchExpression:
+		c.checkSwitch(node, s)
+		return typeUnknown
+
+	case *ast.FunctionLiteral:
+		sig := signatureOf(node, "")
+		if node.Name != nil {
+			s.define(node.Name.Value, &symbol{Type: typeFunc, Sig: sig})
+		}
+		c.checkFunctionBody(node, sig, s)
+		return typeFunc
+
+	case *ast.CallExpression:
+		return c.inferCall(node, s)
+
+	case *ast.NamedArgument:
+		return c.infer(node.Value, s)
+
+	case *ast.MemberExpression:
+		return c.inferMember(node, s)
+
+	case *ast.IndexExpression:
+		left := c.infer(node.Left, s)
+		c.infer(node.Index, s)
+		switch left {
+		case typeText:
+			return typeText
+		case typeNumber, typeBool, typeFunc:
+			c.report(node.Token, "operador de índice não suportado: %s", left)
+		}
+		return typeUnknown
+
+	case *ast.SliceExpression:
+		left := c.infer(node.Left, s)
+		for _, part := range []ast.Expression{node.Start, node.End, node.Step} {
+			if part != nil {
+				c.infer(part, s)
+			}
+		}
+		switch left {
+		case typeText, typeList, typeTuple:
+			return left
+		}
+		return typeUnknown
+
+	case *ast.RangePattern:
+		c.infer(node.Low, s)
+		c.infer(node.High, s)
+		return typeUnknown
+	}
+
+	return typeUnknown
+}
+
+func (c *checker) inferAll(nodes []ast.Expression, s *scope) []string {
+	types := make([]string, len(nodes))
+	for i, node := range nodes {
+		types[i] = c.infer(node, s)
+	}
+	return types
+}
+
+func (c *checker) inferPrefix(node *ast.PrefixExpression, s *scope) string {
+	right := c.infer(node.Right, s)
+	switch node.Operator {
+	case "-", "~":
+		if !c.compatible(typeNumber, right) {
+			c.report(node.Token, "operador %s não suportado para %s", node.Operator, right)
+			return typeUnknown
+		}
+		return typeNumber
+	default:
+		return typeBool
+	}
+}
+
+func (c *checker) inferInfix(node *ast.InfixExpression, s *scope) string {
+	left := c.infer(node.Left, s)
+	right := c.infer(node.Right, s)
+
+	switch node.Operator {
+	case "==", "!=", "é":
+		return typeBool
+	case "em":
+		switch right {
+		case typeUnknown, typeList, typeTuple, typeSet, typeMap:
+		case typeText:
+			if !c.compatible(typeText, left) {
+				c.report(node.Token, "operador em com texto requer texto à esquerda, recebido %s", left)
+			}
+		default:
+			c.report(node.Token, "operador em não suportado: %s", right)
+		}
+		return typeBool
+	}
+
+	if left == typeUnknown || right == typeUnknown || left == typeNull || right == typeNull {
+		if isComparison(node.Operator) {
+			return typeBool
+		}
+		return typeUnknown
+	}
+
+	switch {
+	case left == typeNumber && right == typeNumber:
+		if isComparison(node.Operator) {
+			return typeBool
+		}
+		return typeNumber
+	case left == typeText && right == typeText && (node.Operator == "+" || isComparison(node.Operator)):
+		if node.Operator == "+" {
+			return typeText
+		}
+		return typeBool
+	case left == typeSet && right == typeSet && strings.Contains("|&-^", node.Operator):
+		return typeSet
+	}
+
+	c.report(node.Token, "operador %s não suportado entre %s e %s", node.Operator, left, right)
+	return typeUnknown
+}
+
+func isComparison(operator string) bool {
+	switch operator {
+	case "<", ">", "<=", ">=":
+		return true
+	}
+	return false
+}
+
+func (c *checker) elementType(iterable ast.Expression, s *scope) string {
+	if enum := c.enumOf(iterable, s); enum != nil {
+		return enum.Name
+	}
+
+	switch typ := c.infer(iterable, s); typ {
+	case typeText:
+		return typeText
+	case typeUnknown, typeList, typeTuple, typeSet, typeMap, typeEnum, typeNull:
+	default:
+		c.report(startToken(iterable), "não é possível percorrer %s", typ)
+	}
+	return typeUnknown
+}
+
+// enumOf retorna a enumeração referida diretamente por um identificador.
+func (c *checker) enumOf(node ast.Expression, s *scope) *enumInfo {
+	if ident, ok := node.(*ast.Identifier); ok {
+		if sym, ok := s.lookup(ident.Value); ok {
+			return sym.Enum
+		}
+	}
+	return nil
+}
+
+func (c *checker) inferMember(node *ast.MemberExpression, s *scope) string {
+	if enum := c.enumOf(node.Object, s); enum != nil {
+		if !enum.has(node.Property.Value) {
+			c.report(node.Property.Token, "enumeracao %s não tem o membro %s", enum.Name, node.Property.Value)
+			return typeUnknown
+		}
+		return enum.Name
+	}
+	return c.memberOf(c.infer(node.Object, s), node.Property)
+}
+
+// memberOf retorna o tipo de um membro de um valor do tipo typ.
+func (c *checker) memberOf(typ string, property *ast.Identifier) string {
+	name := property.Value
+
+	if _, ok := c.enums[typ]; ok {
+		switch name {
+		case "nome":
+			return typeText
+		case "ordem":
+			return typeNumber
+		}
+		c.report(property.Token, "membro de enumeracao só tem nome e ordem, recebido %s", name)
+		return typeUnknown
+	}
+
+	if info, ok := c.types[typ]; ok {
+		if field, ok := info.field(name); ok {
+			return field.Type
+		}
+		if _, ok := info.method(name); ok && info.Class {
+			return typeFunc
+		}
+		if info.Class {
+			c.report(property.Token, "objeto %s não tem o membro %s", info.Name, name)
+		} else {
+			c.reportMissingMember(info, property)
+		}
+		return typeUnknown
+	}
+
+	if typ != typeUnknown && typ != typeNull && typ != typeEnum {
+		c.report(property.Token, "acesso a membro não suportado: %s.%s", typ, name)
+	}
+	return typeUnknown
+}
+
+func (c *checker) inferCall(node *ast.CallExpression, s *scope) string {
+	switch callee := node.Function.(type) {
+	case *ast.Identifier:
+		if sym, ok := s.lookup(callee.Value); ok {
+			switch {
+			case sym.Sig != nil:
+				return c.checkCall(sym.Sig, node, s)
+			case sym.Info != nil:
+				return c.checkConstruct(sym.Info, node, s)
+			case sym.Type != typeUnknown && sym.Type != typeFunc:
+				c.report(callee.Token, "%s não é uma função: %s", callee.Value, sym.Type)
+			}
+		}
+
+	case *ast.MemberExpression:
+		if c.enumOf(callee.Object, s) != nil {
+			c.infer(callee, s)
+			break
+		}
+		typ := c.infer(callee.Object, s)
+		if info, ok := c.types[typ]; ok && info.Class {
+			if _, isField := info.field(callee.Property.Value); !isField {
+				if sig, ok := info.method(callee.Property.Value); ok {
+					return c.checkCall(sig, node, s)
+				}
+			}
+		}
+		c.memberOf(typ, callee.Property)
+
+	default:
+		c.infer(callee, s)
+	}
+
+	c.inferAll(node.Arguments, s)
+	return typeUnknown
+}
+
+func splitArguments(arguments []ast.Expression) ([]ast.Expression, []*ast.NamedArgument) {
+	var positional []ast.Expression
+	var named []*ast.NamedArgument
+	for _, arg := range arguments {
+		if namedArg, ok := arg.(*ast.NamedArgument); ok {
+			named = append(named, namedArg)
+			continue
+		}
+		positional = append(positional, arg)
+	}
+	return positional, named
+}
+
+// checkCall confere aridade, argumentos nomeados e tipos de uma chamada a uma
+// função de assinatura conhecida, seguindo as mesmas regras da execução.
+func (c *checker) checkCall(sig *signature, node *ast.CallExpression, s *scope) string {
+	positional, named := splitArguments(node.Arguments)
+	types := c.inferAll(positional, s)
+
+	var variadic *param
+	params := sig.Params
+	if len(params) > 0 && params[len(params)-1].Variadic {
+		variadic = &params[len(params)-1]
+		params = params[:len(params)-1]
+	}
+
+	if sig.Builtin && len(named) > 0 {
+		c.report(named[0].Token, "argumentos nomeados não são aceitos por funções built-in")
+		for _, arg := range named {
+			c.infer(arg.Value, s)
+		}
+		return sig.Result
+	}
+
+	if variadic == nil && len(positional) > len(params) {
+		c.report(startToken(node.Function), "%s: número errado de argumentos. esperado no máximo %d, recebido %d", sig.Name, len(params), len(positional))
+	}
+
+	bound := make([]bool, len(params))
+	for i, arg := range positional {
+		switch {
+		case i < len(params):
+			bound[i] = true
+			c.checkArgument(sig, params[i], arg, types[i])
+		case variadic != nil:
+			c.checkArgument(sig, *variadic, arg, types[i])
+		}
+	}
+
+	for _, arg := range named {
+		actual := c.infer(arg.Value, s)
+		idx := -1
+		for i, p := range params {
+			if p.Name == arg.Name.Value {
+				idx = i
+			}
+		}
+		switch {
+		case idx < 0:
+			c.report(arg.Token, "%s: parâmetro desconhecido: %s", sig.Name, arg.Name.Value)
+		case bound[idx]:
+			c.report(arg.Token, "%s: argumento %s informado mais de uma vez", sig.Name, arg.Name.Value)
+		default:
+			bound[idx] = true
+			c.checkArgument(sig, params[idx], arg.Value, actual)
+		}
+	}
+
+	for i, p := range params {
+		if !bound[i] && !p.Optional {
+			c.report(startToken(node.Function), "%s: argumento obrigatório ausente: %s", sig.Name, p.Name)
+		}
+	}
+
+	if sig.ResultFromArg >= 0 && sig.ResultFromArg < len(types) && types[sig.ResultFromArg] != typeUnknown {
+		return types[sig.ResultFromArg]
+	}
+	return sig.Result
+}
+
+func (c *checker) checkArgument(sig *signature, p param, arg ast.Expression, actual string) {
+	if !c.acceptsAny(p.Types, actual) {
+		c.report(startToken(arg), "%s: parâmetro %s deve ser %s, recebido %s", sig.Name, p.Name, strings.Join(p.Types, " ou "), actual)
+	}
+}
+
+// checkConstruct confere a criação de um registro ou objeto. Classes com
+// construtor são verificadas pela assinatura do construtor.
+func (c *checker) checkConstruct(info *typeInfo, node *ast.CallExpression, s *scope) string {
+	if info.Class {
+		if sig, ok := info.method("construtor"); ok {
+			c.checkCall(sig, node, s)
+			return info.Name
+		}
+	}
+
+	positional, named := splitArguments(node.Arguments)
+	if len(positional) > len(info.Fields) {
+		c.report(startToken(node.Function), "%s tem %d campos, recebidos %d argumentos", info.Name, len(info.Fields), len(positional))
+	}
+
+	bound := make([]bool, len(info.Fields))
+	for i, arg := range positional {
+		actual := c.infer(arg, s)
+		if i >= len(info.Fields) {
+			continue
+		}
+		bound[i] = true
+		c.checkFieldValue(info, info.Fields[i], arg, actual)
+	}
+
+	for _, arg := range named {
+		actual := c.infer(arg.Value, s)
+		idx := -1
+		for i, field := range info.Fields {
+			if field.Name == arg.Name.Value {
+				idx = i
+			}
+		}
+		switch {
+		case idx < 0:
+			c.reportMissingMember(info, arg.Name)
+		case bound[idx]:
+			c.report(arg.Token, "campo %s de %s informado mais de uma vez", arg.Name.Value, info.Name)
+		default:
+			bound[idx] = true
+			c.checkFieldValue(info, info.Fields[idx], arg.Value, actual)
+		}
+	}
+
+	if !info.Class {
+		for i, field := range info.Fields {
+			if !bound[i] && !field.HasDefault {
+				c.report(startToken(node.Function), "campo obrigatório ausente em %s: %s", info.Name, field.Name)
+			}
+		}
+	}
+
+	return info.Name
+}
+
+func (c *checker) checkFieldValue(info *typeInfo, field fieldInfo, arg ast.Expression, actual string) {
+	if !c.compatible(field.Type, actual) {
+		c.report(startToken(arg), "campo %s de %s deve ser %s, recebido %s", field.Name, info.Name, field.Type, actual)
+	}
+}
+
+func (c *checker) checkSwitch(node *ast.SwitchExpression, s *scope) {
+	subject := c.infer(node.Subject, s)
+
+	for _, switchCase := range node.Cases {
+		caseScope := newBlockScope(s)
+		for _, pattern := range switchCase.Patterns {
+			c.bindPattern(pattern, caseScope)
+		}
+		if switchCase.Guard != nil {
+			c.infer(switchCase.Guard, caseScope)
+		}
+		c.checkBlock(switchCase.Body, caseScope)
+	}
+	if node.Default != nil {
+		c.checkBlock(node.Default, newBlockScope(s))
+	}
+
+	if enum, ok := c.enums[subject]; ok && node.Default == nil {
+		c.checkEnumCoverage(node, enum)
+	}
+}
+
+// bindPattern declara os nomes capturados por um padrão de caso.
+func (c *checker) bindPattern(pattern ast.Expression, s *scope) {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		if pattern.Value != "_" {
+			s.define(pattern.Value, &symbol{})
+		}
+	case *ast.ArrayLiteral:
+		for _, element := range pattern.Elements {
+			c.bindPattern(element, s)
+		}
+	case *ast.TupleLiteral:
+		for _, element := range pattern.Elements {
+			c.bindPattern(element, s)
+		}
+	case *ast.HashLiteral:
+		for _, pair := range pattern.Pairs {
+			c.infer(pair.Key, s)
+			c.bindPattern(pair.Value, s)
+		}
+	default:
+		c.infer(pattern, s)
+	}
+}
+
+func (c *checker) checkEnumCoverage(node *ast.SwitchExpression, enum *enumInfo) {
+	covered := map[string]bool{}
+	for _, switchCase := range node.Cases {
+		if switchCase.Guard != nil {
+			continue
+		}
+		for _, pattern := range switchCase.Patterns {
+			switch pattern := pattern.(type) {
+			case *ast.Identifier:
+				return
+			case *ast.MemberExpression:
+				if ident, ok := pattern.Object.(*ast.Identifier); ok && ident.Value == enum.Name {
+					covered[pattern.Property.Value] = true
+				}
+			}
+		}
+	}
+
+	missing := []string{}
+	for _, member := range enum.Members {
+		if !covered[member] {
+			missing = append(missing, member)
+		}
+	}
+	if len(missing) > 0 {
+		c.report(node.Token, "escolha não cobre todos os membros de %s: faltam %s", enum.Name, strings.Join(missing, ", "))
+	}
+}
+
+// startToken retorna o primeiro token de uma expressão, usado para posicionar
+// diagnósticos.
+func startToken(node ast.Expression) token.Token {
+	switch node := node.(type) {
+	case *ast.InfixExpression:
+		return startToken(node.Left)
+	case *ast.LogicalExpression:
+		return startToken(node.Left)
+	case *ast.CallExpression:
+		return startToken(node.Function)
+	case *ast.MemberExpression:
+		return startToken(node.Object)
+	case *ast.IndexExpression:
+		return startToken(node.Left)
+	case *ast.SliceExpression:
+		return startToken(node.Left)
+	case *ast.Identifier:
+		return node.Token
+	case *ast.IntegerLiteral:
+		return node.Token
+	case *ast.FloatLiteral:
+		return node.Token
+	case *ast.StringLiteral:
+		return node.Token
+	case *ast.Boolean:
+		return node.Token
+	case *ast.NullLiteral:
+		return node.Token
+	case *ast.PrefixExpression:
+		return node.Token
+	case *ast.ArrayLiteral:
+		return node.Token
+	case *ast.TupleLiteral:
+		return node.Token
+	case *ast.SetLiteral:
+		return node.Token
+	case *ast.HashLiteral:
+		return node.Token
+	case *ast.FunctionLiteral:
+		return node.Token
+	case *ast.IfExpression:
+		return node.Token
+	case *ast.SwitchExpression:
+		return node.Token
+	case *ast.NamedArgument:
+		return node.Token
+	case *ast.RangePattern:
+		return startToken(node.Low)
+	}
+	return token.Token{}
+}
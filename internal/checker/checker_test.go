@@ -0,0 +1,106 @@
+package checker
+
+import (
+	"testing"
+
+	"sovylang/internal/lexer"
+	"sovylang/internal/parser"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			"declaração incompatível",
+			`numero x = "a"`,
+			[]string{"1:8: x declarado como numero, mas recebe texto"},
+		},
+		{
+			"argumento de built-in",
+			`imprimir(tamanho(5))`,
+			[]string{"1:18: tamanho: parâmetro valor deve ser lista ou tupla ou texto ou mapa ou conjunto ou enumeracao, recebido numero"},
+		},
+		{
+			"assinatura de smath",
+			"sovy smath include\nnumero r = raiz(\"nove\")",
+			[]string{"2:17: raiz: parâmetro valor deve ser numero, recebido texto"},
+		},
+		{
+			"retorno incompatível",
+			"funcao f() -> numero\n    retorne \"a\"\nfim",
+			[]string{"2:5: f: deve retornar numero, retornou texto"},
+		},
+		{
+			"funções sem anotações são aceitas",
+			"funcao f(x)\n    retorne x + 1\nfim\ntexto t = f(1)\nnumero n = f(\"a\")",
+			nil,
+		},
+		{
+			"parâmetro anotado",
+			"funcao dobro(numero x) -> numero\n    retorne x * 2\nfim\ndobro(\"a\")",
+			[]string{"4:7: dobro: parâmetro x deve ser numero, recebido texto"},
+		},
+		{
+			"retorno de tupla",
+			"funcao par() -> tupla\n    retorne 1, 2\nfim",
+			nil,
+		},
+		{
+			"anotação com tipo desconhecido",
+			"funcao f(nmero x)\n    retorne x\nfim",
+			[]string{"1:10: tipo desconhecido: nmero"},
+		},
+	}
+
+	for _, tt := range tests {
+		diagnostics := check(t, tt.input)
+		if len(diagnostics) != len(tt.expected) {
+			t.Errorf("%s: esperados %d problemas, recebidos %d: %v", tt.name, len(tt.expected), len(diagnostics), diagnostics)
+			continue
+		}
+		for i, diagnostic := range diagnostics {
+			if diagnostic.String() != tt.expected[i] {
+				t.Errorf("%s: esperado %q, recebido %q", tt.name, tt.expected[i], diagnostic.String())
+			}
+		}
+	}
+}
+
+// As posições apontam para o início do literal ou do nome, não para o
+// caractere seguinte.
+func TestDiagnosticColumn(t *testing.T) {
+	tests := []struct {
+		input  string
+		line   int
+		column int
+	}{
+		{"sovy smath include\nnumero p = potencia(2,   \"dez\")", 2, 26},
+		{"numero a = 1\nnumero b = tamanho(  a)", 2, 22},
+		{"imprimir(tamanho(  12345))", 1, 20},
+		{"imprimir(tamanho(  1.5))", 1, 20},
+	}
+
+	for _, tt := range tests {
+		diagnostics := check(t, tt.input)
+		if len(diagnostics) != 1 {
+			t.Errorf("%q: esperado 1 problema, recebidos %v", tt.input, diagnostics)
+			continue
+		}
+		if diagnostics[0].Line != tt.line || diagnostics[0].Column != tt.column {
+			t.Errorf("%q: esperado %d:%d, recebido %d:%d", tt.input, tt.line, tt.column, diagnostics[0].Line, diagnostics[0].Column)
+		}
+	}
+}
+
+func check(t *testing.T, input string) []Diagnostic {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("%q: erros de sintaxe: %v", input, p.Errors())
+	}
+	return Check(program)
+}
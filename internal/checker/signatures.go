@@ -0,0 +1,76 @@
+package checker
+
+// param descreve um parâmetro de uma assinatura conhecida. Types vazio aceita
+// qualquer valor.
+type param struct {
+	Name     string
+	Types    []string
+	Optional bool
+	Variadic bool
+}
+
+// signature é a assinatura de uma função conhecida pelo verificador.
+// ResultFromArg >= 0 indica que o resultado tem o tipo daquele argumento.
+type signature struct {
+	Name          string
+	Params        []param
+	Result        string
+	ResultFromArg int
+	Builtin       bool
+}
+
+func builtinSignature(name, result string, params ...param) *signature {
+	return &signature{Name: name, Params: params, Result: result, ResultFromArg: -1, Builtin: true}
+}
+
+func anyOf(types ...string) []string { return types }
+
+var builtinSignatures = map[string]*signature{
+	"imprimir":       builtinSignature("imprimir", typeNull, param{Name: "valores", Variadic: true}),
+	"tamanho":        builtinSignature("tamanho", typeNumber, param{Name: "valor", Types: anyOf(typeList, typeTuple, typeText, typeMap, typeSet, typeEnum)}),
+	"primeiro":       builtinSignature("primeiro", typeUnknown, param{Name: "lista", Types: anyOf(typeList)}),
+	"ultimo":         builtinSignature("ultimo", typeUnknown, param{Name: "lista", Types: anyOf(typeList)}),
+	"resto":          builtinSignature("resto", typeList, param{Name: "lista", Types: anyOf(typeList)}),
+	"adicionar":      {Name: "adicionar", Params: []param{{Name: "colecao", Types: anyOf(typeList, typeSet)}, {Name: "valor"}}, ResultFromArg: 0, Builtin: true},
+	"anexar":         {Name: "anexar", Params: []param{{Name: "colecao", Types: anyOf(typeList, typeSet)}, {Name: "valor"}}, ResultFromArg: 0, Builtin: true},
+	"inserir":        builtinSignature("inserir", typeList, param{Name: "lista", Types: anyOf(typeList)}, param{Name: "indice", Types: anyOf(typeNumber)}, param{Name: "valor"}),
+	"remover":        builtinSignature("remover", typeUnknown, param{Name: "lista", Types: anyOf(typeList)}, param{Name: "indice", Types: anyOf(typeNumber)}),
+	"apagar_chave":   builtinSignature("apagar_chave", typeUnknown, param{Name: "mapa", Types: anyOf(typeMap)}, param{Name: "chave"}),
+	"decimal":        builtinSignature("decimal", typeNumber, param{Name: "valor", Types: anyOf(typeNumber, typeText)}),
+	"arredondar":     builtinSignature("arredondar", typeNumber, param{Name: "valor", Types: anyOf(typeNumber)}, param{Name: "casas", Types: anyOf(typeNumber)}, param{Name: "modo", Types: anyOf(typeText), Optional: true}),
+	"formatar_moeda": builtinSignature("formatar_moeda", typeText, param{Name: "valor", Types: anyOf(typeNumber)}, param{Name: "modo", Types: anyOf(typeText), Optional: true}),
+	"para_texto":     builtinSignature("para_texto", typeText, param{Name: "valor"}),
+	"conjunto":       builtinSignature("conjunto", typeSet, param{Name: "valores", Types: iterables, Optional: true}),
+	"descartar":      builtinSignature("descartar", typeBool, param{Name: "conjunto", Types: anyOf(typeSet)}, param{Name: "valor"}),
+	"uniao":          builtinSignature("uniao", typeSet, param{Name: "a", Types: anyOf(typeSet)}, param{Name: "b", Types: anyOf(typeSet)}),
+	"intersecao":     builtinSignature("intersecao", typeSet, param{Name: "a", Types: anyOf(typeSet)}, param{Name: "b", Types: anyOf(typeSet)}),
+	"diferenca":      builtinSignature("diferenca", typeSet, param{Name: "a", Types: anyOf(typeSet)}, param{Name: "b", Types: anyOf(typeSet)}),
+	"tupla":          builtinSignature("tupla", typeTuple, param{Name: "lista", Types: anyOf(typeList)}),
+	"mapear":         builtinSignature("mapear", typeList, param{Name: "colecao", Types: iterables}, param{Name: "funcao", Types: anyOf(typeFunc)}),
+	"filtrar":        builtinSignature("filtrar", typeList, param{Name: "colecao", Types: iterables}, param{Name: "funcao", Types: anyOf(typeFunc)}),
+	"reduzir":        builtinSignature("reduzir", typeUnknown, param{Name: "colecao", Types: iterables}, param{Name: "funcao", Types: anyOf(typeFunc)}, param{Name: "inicial", Optional: true}),
+	"ordenar":        builtinSignature("ordenar", typeList, param{Name: "colecao", Types: iterables}, param{Name: "comparador", Types: anyOf(typeFunc), Optional: true}),
+	"algum":          builtinSignature("algum", typeBool, param{Name: "colecao", Types: iterables}, param{Name: "funcao", Types: anyOf(typeFunc)}),
+	"todos":          builtinSignature("todos", typeBool, param{Name: "colecao", Types: iterables}, param{Name: "funcao", Types: anyOf(typeFunc)}),
+	"encontrar":      builtinSignature("encontrar", typeUnknown, param{Name: "colecao", Types: iterables}, param{Name: "funcao", Types: anyOf(typeFunc)}),
+	"agrupar_por":    builtinSignature("agrupar_por", typeMap, param{Name: "colecao", Types: iterables}, param{Name: "funcao", Types: anyOf(typeFunc)}),
+}
+
+// iterables são os tipos aceitos por para cada.
+var iterables = anyOf(typeList, typeTuple, typeSet, typeMap, typeText, typeEnum)
+
+// librarySignatures lista as funções das bibliotecas carregadas com
+// "sovy <biblioteca> include".
+var librarySignatures = map[string]map[string]*signature{
+	"smath": {
+		"potencia": builtinSignature("potencia", typeNumber, param{Name: "base", Types: anyOf(typeNumber)}, param{Name: "expoente", Types: anyOf(typeNumber)}),
+		"raiz":     builtinSignature("raiz", typeNumber, param{Name: "valor", Types: anyOf(typeNumber)}),
+		"sin":      builtinSignature("sin", typeNumber, param{Name: "valor", Types: anyOf(typeNumber)}),
+		"cos":      builtinSignature("cos", typeNumber, param{Name: "valor", Types: anyOf(typeNumber)}),
+		"tan":      builtinSignature("tan", typeNumber, param{Name: "valor", Types: anyOf(typeNumber)}),
+		"abs":      builtinSignature("abs", typeNumber, param{Name: "valor", Types: anyOf(typeNumber)}),
+		"max":      builtinSignature("max", typeNumber, param{Name: "a", Types: anyOf(typeNumber)}, param{Name: "b", Types: anyOf(typeNumber)}),
+		"min":      builtinSignature("min", typeNumber, param{Name: "a", Types: anyOf(typeNumber)}, param{Name: "b", Types: anyOf(typeNumber)}),
+		"pi":       builtinSignature("pi", typeNumber),
+	},
+}
@@ -0,0 +1,135 @@
+package collate
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Compare ordena textos seguindo as regras usuais do português brasileiro:
+// primeiro pelas letras base sem acento e sem diferenciar maiúsculas, depois
+// pelos acentos e por último pela caixa, com minúsculas antes de maiúsculas.
+// Assim "arvore" < "árvore" < "Árvore" < "avião".
+func Compare(a, b string) int {
+	ka, kb := keys(a), keys(b)
+
+	if cmp := compareLevel(ka, kb, func(k key) int { return k.primary }); cmp != 0 {
+		return cmp
+	}
+	if cmp := compareLevel(ka, kb, func(k key) int { return k.accent }); cmp != 0 {
+		return cmp
+	}
+	if cmp := compareLevel(ka, kb, func(k key) int { return k.upper }); cmp != 0 {
+		return cmp
+	}
+
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+type key struct {
+	primary int
+	accent  int
+	upper   int
+}
+
+const (
+	accentNone = iota
+	accentAcute
+	accentGrave
+	accentCircumflex
+	accentDiaeresis
+	accentTilde
+	accentRing
+	accentCedilla
+)
+
+type decomposition struct {
+	base   rune
+	accent int
+}
+
+var decompositions = map[rune]decomposition{
+	'á': {'a', accentAcute}, 'à': {'a', accentGrave}, 'â': {'a', accentCircumflex},
+	'ã': {'a', accentTilde}, 'ä': {'a', accentDiaeresis}, 'å': {'a', accentRing},
+	'é': {'e', accentAcute}, 'è': {'e', accentGrave}, 'ê': {'e', accentCircumflex},
+	'ë': {'e', accentDiaeresis},
+	'í': {'i', accentAcute}, 'ì': {'i', accentGrave}, 'î': {'i', accentCircumflex},
+	'ï': {'i', accentDiaeresis},
+	'ó': {'o', accentAcute}, 'ò': {'o', accentGrave}, 'ô': {'o', accentCircumflex},
+	'õ': {'o', accentTilde}, 'ö': {'o', accentDiaeresis},
+	'ú': {'u', accentAcute}, 'ù': {'u', accentGrave}, 'û': {'u', accentCircumflex},
+	'ü': {'u', accentDiaeresis},
+	'ý': {'y', accentAcute}, 'ÿ': {'y', accentDiaeresis},
+	'ç': {'c', accentCedilla}, 'ñ': {'n', accentTilde},
+}
+
+// Classes de caracteres na ordem em que aparecem na ordenação: espaços e
+// pontuação, símbolos, dígitos e letras.
+const (
+	classSpace = iota
+	classPunct
+	classSymbol
+	classDigit
+	classLetter
+)
+
+func keys(s string) []key {
+	result := make([]key, 0, utf8.RuneCountInString(s))
+
+	for _, r := range s {
+		k := key{}
+		lower := unicode.ToLower(r)
+		if lower != r {
+			k.upper = 1
+		}
+
+		if d, ok := decompositions[lower]; ok {
+			lower = d.base
+			k.accent = d.accent
+		}
+
+		class := classLetter
+		switch {
+		case unicode.IsSpace(lower):
+			class = classSpace
+		case unicode.IsPunct(lower):
+			class = classPunct
+		case unicode.IsSymbol(lower):
+			class = classSymbol
+		case unicode.IsDigit(lower):
+			class = classDigit
+		}
+
+		k.primary = class<<24 | int(lower)
+		result = append(result, k)
+	}
+
+	return result
+}
+
+func compareLevel(a, b []key, weight func(key) int) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		wa, wb := weight(a[i]), weight(b[i])
+		if wa < wb {
+			return -1
+		}
+		if wa > wb {
+			return 1
+		}
+	}
+
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,258 @@
+package decimal
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// DivisionScale é o número mínimo de casas decimais mantidas no resultado de
+// uma divisão que não é exata.
+const DivisionScale = 16
+
+type RoundingMode int
+
+const (
+	HalfEven RoundingMode = iota
+	HalfUp
+)
+
+var roundingModes = map[string]RoundingMode{
+	"meio_par":   HalfEven,
+	"meio_acima": HalfUp,
+}
+
+func ParseRoundingMode(name string) (RoundingMode, bool) {
+	mode, ok := roundingModes[name]
+	return mode, ok
+}
+
+// Decimal representa o número value × 10^-scale sem perda de precisão.
+type Decimal struct {
+	value *big.Int
+	scale int
+}
+
+func New(value *big.Int, scale int) Decimal {
+	return Decimal{value: new(big.Int).Set(value), scale: scale}
+}
+
+func FromInt(value *big.Int) Decimal {
+	return New(value, 0)
+}
+
+func FromFloat(value float64) (Decimal, error) {
+	return Parse(strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+// Parse aceita tanto "1234.56" quanto o formato brasileiro "1.234,56". Quando
+// há vírgula, ela é o separador decimal e os pontos são separadores de milhar.
+func Parse(input string) (Decimal, error) {
+	s := strings.TrimSpace(input)
+	if strings.Contains(s, ",") {
+		s = strings.ReplaceAll(s, ".", "")
+		s = strings.Replace(s, ",", ".", 1)
+	}
+
+	sign := ""
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		sign, s = s[:1], s[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if intPart == "" && fracPart == "" || !isDigits(intPart) || !isDigits(fracPart) {
+		return Decimal{}, fmt.Errorf("valor decimal inválido: %q", input)
+	}
+
+	value, ok := new(big.Int).SetString(sign+intPart+fracPart, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("valor decimal inválido: %q", input)
+	}
+
+	return Decimal{value: value, scale: len(fracPart)}, nil
+}
+
+func isDigits(s string) bool {
+	for _, ch := range s {
+		if ch < '0' || ch > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (d Decimal) Scale() int { return d.scale }
+func (d Decimal) Sign() int  { return d.value.Sign() }
+
+func (d Decimal) Add(other Decimal) Decimal {
+	a, b := align(d, other)
+	return Decimal{value: new(big.Int).Add(a.value, b.value), scale: a.scale}
+}
+
+func (d Decimal) Sub(other Decimal) Decimal {
+	a, b := align(d, other)
+	return Decimal{value: new(big.Int).Sub(a.value, b.value), scale: a.scale}
+}
+
+func (d Decimal) Mul(other Decimal) Decimal {
+	return Decimal{value: new(big.Int).Mul(d.value, other.value), scale: d.scale + other.scale}
+}
+
+// Div divide mantendo pelo menos DivisionScale casas e depois remove os zeros
+// à direita que excedem a escala dos operandos, de modo que 10.00 / 4 resulta
+// em 2.50 e 10 / 3 em 3.3333333333333333.
+func (d Decimal) Div(other Decimal, mode RoundingMode) (Decimal, error) {
+	if other.value.Sign() == 0 {
+		return Decimal{}, fmt.Errorf("divisão por zero")
+	}
+
+	minScale := max(d.scale, other.scale)
+	scale := max(minScale, DivisionScale)
+
+	numerator := new(big.Int).Mul(d.value, pow10(scale+other.scale-d.scale))
+	quotient, remainder := new(big.Int).QuoRem(numerator, other.value, new(big.Int))
+	if roundsAway(quotient, remainder, other.value, mode) {
+		quotient.Add(quotient, big.NewInt(int64(numerator.Sign()*other.value.Sign())))
+	}
+	result := Decimal{value: quotient, scale: scale}
+
+	for result.scale > minScale {
+		q, r := new(big.Int).QuoRem(result.value, big.NewInt(10), new(big.Int))
+		if r.Sign() != 0 {
+			break
+		}
+		result = Decimal{value: q, scale: result.scale - 1}
+	}
+
+	return result, nil
+}
+
+// Rem devolve o resto de d / other com o sinal de d, como o % de inteiros:
+// 10.5 % 3 resulta em 1.5.
+func (d Decimal) Rem(other Decimal) (Decimal, error) {
+	if other.value.Sign() == 0 {
+		return Decimal{}, fmt.Errorf("divisão por zero")
+	}
+	a, b := align(d, other)
+	return Decimal{value: new(big.Int).Rem(a.value, b.value), scale: a.scale}, nil
+}
+
+// Round arredonda para o número de casas indicado. Quando a precisão atual já
+// é menor ou igual, o valor é estendido com zeros.
+func (d Decimal) Round(places int, mode RoundingMode) Decimal {
+	if places >= d.scale {
+		return Decimal{value: new(big.Int).Mul(d.value, pow10(places-d.scale)), scale: places}
+	}
+
+	divisor := pow10(d.scale - places)
+	quotient, remainder := new(big.Int).QuoRem(d.value, divisor, new(big.Int))
+	if roundsAway(quotient, remainder, divisor, mode) {
+		quotient.Add(quotient, big.NewInt(int64(d.value.Sign())))
+	}
+
+	return Decimal{value: quotient, scale: places}
+}
+
+// roundsAway decide, a partir do resto de uma divisão truncada, se o
+// quociente deve ser afastado de zero.
+func roundsAway(quotient, remainder, divisor *big.Int, mode RoundingMode) bool {
+	if remainder.Sign() == 0 {
+		return false
+	}
+
+	twice := new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2))
+	cmp := twice.Cmp(new(big.Int).Abs(divisor))
+
+	switch mode {
+	case HalfUp:
+		return cmp >= 0
+	default:
+		return cmp > 0 || cmp == 0 && quotient.Bit(0) == 1
+	}
+}
+
+func (d Decimal) Neg() Decimal {
+	return Decimal{value: new(big.Int).Neg(d.value), scale: d.scale}
+}
+
+func (d Decimal) Cmp(other Decimal) int {
+	a, b := align(d, other)
+	return a.value.Cmp(b.value)
+}
+
+// IsInteger informa se o valor não tem parte fracionária, independentemente
+// da escala (2.00 é inteiro).
+func (d Decimal) IsInteger() bool {
+	if d.scale <= 0 {
+		return true
+	}
+	return new(big.Int).Rem(d.value, pow10(d.scale)).Sign() == 0
+}
+
+// Integer devolve a parte inteira, truncada em direção a zero.
+func (d Decimal) Integer() *big.Int {
+	if d.scale <= 0 {
+		return new(big.Int).Mul(d.value, pow10(-d.scale))
+	}
+	return new(big.Int).Quo(d.value, pow10(d.scale))
+}
+
+func (d Decimal) Float64() float64 {
+	value, _ := strconv.ParseFloat(d.String(), 64)
+	return value
+}
+
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.value).String()
+	sign := ""
+	if d.value.Sign() < 0 {
+		sign = "-"
+	}
+
+	if d.scale <= 0 {
+		return sign + digits + strings.Repeat("0", -d.scale)
+	}
+
+	if len(digits) <= d.scale {
+		digits = strings.Repeat("0", d.scale-len(digits)+1) + digits
+	}
+
+	point := len(digits) - d.scale
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// FormatBRL formata o valor como moeda brasileira, por exemplo "R$ 1.234,56".
+func (d Decimal) FormatBRL(mode RoundingMode) string {
+	rounded := d.Round(2, mode)
+	intPart, fracPart, _ := strings.Cut(rounded.String(), ".")
+
+	sign := ""
+	if strings.HasPrefix(intPart, "-") {
+		sign, intPart = "-", intPart[1:]
+	}
+
+	var groups []string
+	for len(intPart) > 3 {
+		groups = append([]string{intPart[len(intPart)-3:]}, groups...)
+		intPart = intPart[:len(intPart)-3]
+	}
+	groups = append([]string{intPart}, groups...)
+
+	return sign + "R$ " + strings.Join(groups, ".") + "," + fracPart
+}
+
+func align(a, b Decimal) (Decimal, Decimal) {
+	switch {
+	case a.scale < b.scale:
+		return a.Round(b.scale, HalfEven), b
+	case a.scale > b.scale:
+		return a, b.Round(a.scale, HalfEven)
+	default:
+		return a, b
+	}
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
@@ -0,0 +1,61 @@
+package evaluator_test
+
+import (
+	"testing"
+
+	"sovylang/internal/evaluator"
+	"sovylang/internal/lexer"
+	"sovylang/internal/parser"
+	"sovylang/internal/resolver"
+)
+
+const loopScript = `
+numero total = 0
+para numero i = 1 ate 200000
+    numero quadrado = i * i
+    se quadrado % 3 == 0
+        total = total + quadrado
+    senao
+        total = total - i
+    fim
+fim
+total
+`
+
+const callScript = `
+funcao fib(n)
+    se n < 2
+        retorne n
+    fim
+    retorne fib(n - 1) + fib(n - 2)
+fim
+fib(20)
+`
+
+func BenchmarkLoopByName(b *testing.B)    { benchmarkScript(b, loopScript, false) }
+func BenchmarkLoopResolved(b *testing.B)  { benchmarkScript(b, loopScript, true) }
+func BenchmarkCallsByName(b *testing.B)   { benchmarkScript(b, callScript, false) }
+func BenchmarkCallsResolved(b *testing.B) { benchmarkScript(b, callScript, true) }
+
+// benchmarkScript mede só a execução: a análise sintática e a resolução
+// ficam fora do tempo medido.
+func benchmarkScript(b *testing.B, input string, resolve bool) {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		p := parser.New(lexer.New(input))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			b.Fatalf("erros de sintaxe: %v", p.Errors())
+		}
+		if resolve {
+			if errors := resolver.Resolve(program); len(errors) != 0 {
+				b.Fatalf("erros de resolução: %v", errors)
+			}
+		}
+		b.StartTimer()
+
+		if result := evaluator.New().Eval(program); result != nil && result.Type() == "ERROR" {
+			b.Fatalf("erro de execução: %s", result.Inspect())
+		}
+	}
+}
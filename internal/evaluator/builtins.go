@@ -2,12 +2,22 @@ package evaluator
 
 import (
 	"fmt"
+	"sort"
+	"sovylang/internal/collate"
+	"sovylang/internal/decimal"
 	"sovylang/internal/object"
+	"unicode/utf8"
 )
 
+// IsBuiltin diz se name é uma função built-in.
+func IsBuiltin(name string) bool {
+	_, ok := builtins[name]
+	return ok
+}
+
 var builtins = map[string]*object.Builtin{
 	"imprimir": {
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
 			for _, arg := range args {
 				fmt.Println(arg.Inspect())
 			}
@@ -15,7 +25,7 @@ var builtins = map[string]*object.Builtin{
 		},
 	},
 	"tamanho": {
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
 			if len(args) != 1 {
 				return newError("número errado de argumentos. esperado=1, recebido=%d", len(args))
 			}
@@ -23,15 +33,23 @@ var builtins = map[string]*object.Builtin{
 			switch arg := args[0].(type) {
 			case *object.Array:
 				return &object.Integer{Value: int64(len(arg.Elements))}
+			case *object.Tuple:
+				return &object.Integer{Value: int64(len(arg.Elements))}
 			case *object.String:
-				return &object.Integer{Value: int64(len(arg.Value))}
+				return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
+			case *object.Hash:
+				return &object.Integer{Value: int64(arg.Len())}
+			case *object.Set:
+				return &object.Integer{Value: int64(arg.Len())}
+			case *object.Enum:
+				return &object.Integer{Value: int64(len(arg.Members))}
 			default:
 				return newError("argumento para `tamanho` não suportado, recebido %s", args[0].Type())
 			}
 		},
 	},
 	"primeiro": {
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
 			if len(args) != 1 {
 				return newError("número errado de argumentos. esperado=1, recebido=%d", len(args))
 			}
@@ -48,7 +66,7 @@ var builtins = map[string]*object.Builtin{
 		},
 	},
 	"ultimo": {
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
 			if len(args) != 1 {
 				return newError("número errado de argumentos. esperado=1, recebido=%d", len(args))
 			}
@@ -66,7 +84,7 @@ var builtins = map[string]*object.Builtin{
 		},
 	},
 	"resto": {
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
 			if len(args) != 1 {
 				return newError("número errado de argumentos. esperado=1, recebido=%d", len(args))
 			}
@@ -86,12 +104,20 @@ var builtins = map[string]*object.Builtin{
 		},
 	},
 	"adicionar": {
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
 			if len(args) != 2 {
 				return newError("número errado de argumentos. esperado=2, recebido=%d", len(args))
 			}
+			if set, ok := args[0].(*object.Set); ok {
+				if !object.IsHashable(args[1]) {
+					return invalidSetElementError(args[1])
+				}
+				result := setUnion(set, object.NewSet())
+				result.Add(args[1])
+				return result
+			}
 			if args[0].Type() != object.ARRAY_OBJ {
-				return newError("argumento para `adicionar` deve ser ARRAY, recebido %s", args[0].Type())
+				return newError("argumento para `adicionar` deve ser ARRAY ou SET, recebido %s", args[0].Type())
 			}
 
 			arr := args[0].(*object.Array)
@@ -104,4 +130,498 @@ var builtins = map[string]*object.Builtin{
 			return &object.Array{Elements: newElements}
 		},
 	},
+	"anexar": {
+		Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("número errado de argumentos. esperado=2, recebido=%d", len(args))
+			}
+			if set, ok := args[0].(*object.Set); ok {
+				if !object.IsHashable(args[1]) {
+					return invalidSetElementError(args[1])
+				}
+				set.Add(args[1])
+				return set
+			}
+			if args[0].Type() != object.ARRAY_OBJ {
+				return newError("argumento para `anexar` deve ser ARRAY ou SET, recebido %s", args[0].Type())
+			}
+
+			arr := args[0].(*object.Array)
+			arr.Elements = append(arr.Elements, args[1])
+
+			return arr
+		},
+	},
+	"inserir": {
+		Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
+			if len(args) != 3 {
+				return newError("número errado de argumentos. esperado=3, recebido=%d", len(args))
+			}
+			if args[0].Type() != object.ARRAY_OBJ {
+				return newError("argumento para `inserir` deve ser ARRAY, recebido %s", args[0].Type())
+			}
+			if args[1].Type() != object.INTEGER_OBJ {
+				return newError("índice para `inserir` deve ser INTEGER, recebido %s", args[1].Type())
+			}
+
+			arr := args[0].(*object.Array)
+			idx := args[1].(*object.Integer).Value
+			length := int64(len(arr.Elements))
+
+			if idx < 0 || idx > length {
+				return newError("índice fora do intervalo: %d (tamanho %d)", idx, length)
+			}
+
+			arr.Elements = append(arr.Elements, nil)
+			copy(arr.Elements[idx+1:], arr.Elements[idx:])
+			arr.Elements[idx] = args[2]
+
+			return arr
+		},
+	},
+	"remover": {
+		Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("número errado de argumentos. esperado=2, recebido=%d", len(args))
+			}
+			if args[0].Type() != object.ARRAY_OBJ {
+				return newError("argumento para `remover` deve ser ARRAY, recebido %s", args[0].Type())
+			}
+			if args[1].Type() != object.INTEGER_OBJ {
+				return newError("índice para `remover` deve ser INTEGER, recebido %s", args[1].Type())
+			}
+
+			arr := args[0].(*object.Array)
+			idx, ok := normalizeIndex(args[1].(*object.Integer).Value, len(arr.Elements))
+			if !ok {
+				return newError("índice fora do intervalo: %s (tamanho %d)", args[1].Inspect(), len(arr.Elements))
+			}
+
+			removed := arr.Elements[idx]
+			arr.Elements = append(arr.Elements[:idx], arr.Elements[idx+1:]...)
+
+			return removed
+		},
+	},
+	"apagar_chave": {
+		Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("número errado de argumentos. esperado=2, recebido=%d", len(args))
+			}
+			if args[0].Type() != object.HASH_OBJ {
+				return newError("argumento para `apagar_chave` deve ser HASH, recebido %s", args[0].Type())
+			}
+
+			hash := args[0].(*object.Hash)
+			if !object.IsHashable(args[1]) {
+				return invalidHashKeyError(args[1])
+			}
+
+			pair, ok := hash.Delete(args[1])
+			if !ok {
+				return NULL
+			}
+
+			return pair.Value
+		},
+	},
+	"decimal": {
+		Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("número errado de argumentos. esperado=1, recebido=%d", len(args))
+			}
+
+			value, err := object.ToDecimal(args[0])
+			if err != nil {
+				return newError(err.Error())
+			}
+
+			return &object.Decimal{Value: value}
+		},
+	},
+	"arredondar": {
+		Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
+			if len(args) < 2 || len(args) > 3 {
+				return newError("número errado de argumentos. esperado=2 ou 3, recebido=%d", len(args))
+			}
+
+			places, ok := args[1].(*object.Integer)
+			if !ok || places.Value < 0 {
+				return newError("casas decimais de `arredondar` devem ser um inteiro não negativo, recebido %s", args[1].Inspect())
+			}
+
+			mode, err := roundingModeArg(args, 2)
+			if err != nil {
+				return err
+			}
+
+			switch arg := args[0].(type) {
+			case *object.Integer, *object.BigInteger:
+				return arg
+			case *object.Decimal:
+				return &object.Decimal{Value: arg.Value.Round(int(places.Value), mode)}
+			case *object.Float:
+				value, convErr := object.ToDecimal(arg)
+				if convErr != nil {
+					return newError(convErr.Error())
+				}
+				return &object.Float{Value: value.Round(int(places.Value), mode).Float64()}
+			default:
+				return newError("argumento para `arredondar` deve ser um número, recebido %s", args[0].Type())
+			}
+		},
+	},
+	"formatar_moeda": {
+		Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
+			if len(args) < 1 || len(args) > 2 {
+				return newError("número errado de argumentos. esperado=1 ou 2, recebido=%d", len(args))
+			}
+
+			if !object.IsNumber(args[0]) {
+				return newError("argumento para `formatar_moeda` deve ser um número, recebido %s", args[0].Type())
+			}
+
+			value, convErr := object.ToDecimal(args[0])
+			if convErr != nil {
+				return newError(convErr.Error())
+			}
+
+			mode, err := roundingModeArg(args, 1)
+			if err != nil {
+				return err
+			}
+
+			return &object.String{Value: value.FormatBRL(mode)}
+		},
+	},
+	"para_texto": {
+		Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("número errado de argumentos. esperado=1, recebido=%d", len(args))
+			}
+
+			return &object.String{Value: args[0].Inspect()}
+		},
+	},
+	"conjunto": {
+		Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
+			if len(args) == 0 {
+				return object.NewSet()
+			}
+			if len(args) != 1 {
+				return newError("número errado de argumentos. esperado=1, recebido=%d", len(args))
+			}
+
+			elements, err := iterate(args[0])
+			if err != nil {
+				return newError("argumento para `conjunto` não suportado, recebido %s", args[0].Type())
+			}
+
+			return newSet(elements)
+		},
+	},
+	"descartar": {
+		Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("número errado de argumentos. esperado=2, recebido=%d", len(args))
+			}
+			if args[0].Type() != object.SET_OBJ {
+				return newError("argumento para `descartar` deve ser SET, recebido %s", args[0].Type())
+			}
+			if !object.IsHashable(args[1]) {
+				return FALSE
+			}
+
+			return nativeBoolToPyObject(args[0].(*object.Set).Remove(args[1]))
+		},
+	},
+	"uniao":      setBuiltin("uniao", setUnion),
+	"intersecao": setBuiltin("intersecao", setIntersection),
+	"diferenca":  setBuiltin("diferenca", setDifference),
+	"tupla": {
+		Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("número errado de argumentos. esperado=1, recebido=%d", len(args))
+			}
+
+			switch arg := args[0].(type) {
+			case *object.Array:
+				elements := make([]object.Object, len(arg.Elements))
+				copy(elements, arg.Elements)
+				return &object.Tuple{Elements: elements}
+			case *object.Tuple:
+				return arg
+			default:
+				return newError("argumento para `tupla` deve ser ARRAY, recebido %s", args[0].Type())
+			}
+		},
+	},
+	"mapear": {
+		Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
+			elements, err := callbackArgs("mapear", args)
+			if err != nil {
+				return err
+			}
+
+			result := make([]object.Object, len(elements))
+			for i, element := range elements {
+				value := ctx.Call(args[1], element)
+				if isError(value) {
+					return value
+				}
+				result[i] = value
+			}
+			return &object.Array{Elements: result}
+		},
+	},
+	"filtrar": {
+		Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
+			elements, err := callbackArgs("filtrar", args)
+			if err != nil {
+				return err
+			}
+
+			result := []object.Object{}
+			for _, element := range elements {
+				keep := ctx.Call(args[1], element)
+				if isError(keep) {
+					return keep
+				}
+				if isTruthy(keep) {
+					result = append(result, element)
+				}
+			}
+			return &object.Array{Elements: result}
+		},
+	},
+	"reduzir": {
+		Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
+			if len(args) < 2 || len(args) > 3 {
+				return newError("número errado de argumentos. esperado=2 ou 3, recebido=%d", len(args))
+			}
+			elements, err := callbackArgs("reduzir", args[:2])
+			if err != nil {
+				return err
+			}
+
+			var acc object.Object
+			if len(args) == 3 {
+				acc = args[2]
+			} else {
+				if len(elements) == 0 {
+					return newError("`reduzir` de coleção vazia precisa de um valor inicial")
+				}
+				acc, elements = elements[0], elements[1:]
+			}
+
+			for _, element := range elements {
+				acc = ctx.Call(args[1], acc, element)
+				if isError(acc) {
+					return acc
+				}
+			}
+			return acc
+		},
+	},
+	"ordenar": {
+		Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
+			if len(args) < 1 || len(args) > 2 {
+				return newError("número errado de argumentos. esperado=1 ou 2, recebido=%d", len(args))
+			}
+			if len(args) == 2 && !isCallable(args[1]) {
+				return newError("comparador de `ordenar` deve ser uma função, recebido %s", args[1].Type())
+			}
+			elements, err := iterate(args[0])
+			if err != nil {
+				return err
+			}
+
+			var sortErr object.Object
+			sort.SliceStable(elements, func(i, j int) bool {
+				if sortErr != nil {
+					return false
+				}
+				var cmp int
+				if len(args) == 2 {
+					cmp, sortErr = callComparator(ctx, args[1], elements[i], elements[j])
+				} else {
+					cmp, sortErr = compareValues(elements[i], elements[j])
+				}
+				return cmp < 0
+			})
+			if sortErr != nil {
+				return sortErr
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+	"algum": {
+		Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
+			elements, err := callbackArgs("algum", args)
+			if err != nil {
+				return err
+			}
+
+			for _, element := range elements {
+				result := ctx.Call(args[1], element)
+				if isError(result) {
+					return result
+				}
+				if isTruthy(result) {
+					return TRUE
+				}
+			}
+			return FALSE
+		},
+	},
+	"todos": {
+		Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
+			elements, err := callbackArgs("todos", args)
+			if err != nil {
+				return err
+			}
+
+			for _, element := range elements {
+				result := ctx.Call(args[1], element)
+				if isError(result) {
+					return result
+				}
+				if !isTruthy(result) {
+					return FALSE
+				}
+			}
+			return TRUE
+		},
+	},
+	"encontrar": {
+		Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
+			elements, err := callbackArgs("encontrar", args)
+			if err != nil {
+				return err
+			}
+
+			for _, element := range elements {
+				result := ctx.Call(args[1], element)
+				if isError(result) {
+					return result
+				}
+				if isTruthy(result) {
+					return element
+				}
+			}
+			return NULL
+		},
+	},
+	"agrupar_por": {
+		Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
+			elements, err := callbackArgs("agrupar_por", args)
+			if err != nil {
+				return err
+			}
+
+			groups := object.NewHash()
+			for _, element := range elements {
+				key := ctx.Call(args[1], element)
+				if isError(key) {
+					return key
+				}
+				if !object.IsHashable(key) {
+					return invalidHashKeyError(key)
+				}
+				if pair, ok := groups.Get(key); ok {
+					group := pair.Value.(*object.Array)
+					group.Elements = append(group.Elements, element)
+					continue
+				}
+				groups.Set(key, &object.Array{Elements: []object.Object{element}})
+			}
+			return groups
+		},
+	},
+}
+
+func isCallable(obj object.Object) bool {
+	switch obj.(type) {
+	case *object.Function, *object.Builtin, *object.BoundMethod:
+		return true
+	}
+	return false
+}
+
+// callbackArgs valida os argumentos (coleção, função) das funções de ordem
+// superior e retorna os elementos da coleção.
+func callbackArgs(name string, args []object.Object) ([]object.Object, *object.Error) {
+	if len(args) != 2 {
+		return nil, newError("número errado de argumentos. esperado=2, recebido=%d", len(args))
+	}
+	if !isCallable(args[1]) {
+		return nil, newError("segundo argumento de `%s` deve ser uma função, recebido %s", name, args[1].Type())
+	}
+	return iterate(args[0])
+}
+
+// callComparator chama o comparador de ordenar, que deve retornar um número
+// negativo, zero ou positivo.
+func callComparator(ctx *object.CallContext, fn, a, b object.Object) (int, object.Object) {
+	result := ctx.Call(fn, a, b)
+	if isError(result) {
+		return 0, result
+	}
+	cmp, ok := object.CompareNumbers(result, &object.Integer{Value: 0})
+	if !ok {
+		return 0, newError("comparador de `ordenar` deve retornar um número, recebido %s", result.Type())
+	}
+	return cmp, nil
+}
+
+// compareValues define a ordem natural usada por ordenar: números entre si e
+// textos entre si.
+func compareValues(a, b object.Object) (int, object.Object) {
+	if cmp, ok := object.CompareNumbers(a, b); ok {
+		return cmp, nil
+	}
+	left, leftOk := a.(*object.String)
+	right, rightOk := b.(*object.String)
+	if leftOk && rightOk {
+		return collate.Compare(left.Value, right.Value), nil
+	}
+	return 0, newError("`ordenar` não sabe comparar %s com %s; informe um comparador", a.Type(), b.Type())
+}
+
+func setBuiltin(name string, op func(left, right *object.Set) *object.Set) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("número errado de argumentos. esperado=2, recebido=%d", len(args))
+			}
+			left, ok := args[0].(*object.Set)
+			if !ok {
+				return newError("argumento para `%s` deve ser SET, recebido %s", name, args[0].Type())
+			}
+			right, ok := args[1].(*object.Set)
+			if !ok {
+				return newError("argumento para `%s` deve ser SET, recebido %s", name, args[1].Type())
+			}
+
+			return op(left, right)
+		},
+	}
+}
+
+func roundingModeArg(args []object.Object, idx int) (decimal.RoundingMode, *object.Error) {
+	if len(args) <= idx {
+		return decimal.HalfEven, nil
+	}
+
+	name, ok := args[idx].(*object.String)
+	if !ok {
+		return decimal.HalfEven, newError("modo de arredondamento deve ser texto, recebido %s", args[idx].Type())
+	}
+
+	mode, ok := decimal.ParseRoundingMode(name.Value)
+	if !ok {
+		return decimal.HalfEven, newError("modo de arredondamento desconhecido: %q (use \"meio_par\" ou \"meio_acima\")", name.Value)
+	}
+
+	return mode, nil
 }
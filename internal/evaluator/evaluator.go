@@ -2,29 +2,55 @@ package evaluator
 
 import (
 	"fmt"
+	"math"
+	"math/big"
 	"sovylang/internal/ast"
+	"sovylang/internal/collate"
+	"sovylang/internal/decimal"
 	"sovylang/internal/library"
 	"sovylang/internal/object"
+	"sovylang/internal/token"
+	"strings"
 )
 
+// maxShift limita o deslocamento de bits para evitar alocar inteiros gigantes
+// por engano (por exemplo, 1 << 10000000000).
+const maxShift = 1 << 20
+
 var (
 	NULL  = &object.Null{}
 	TRUE  = &object.Boolean{Value: true}
 	FALSE = &object.Boolean{Value: false}
 )
 
+// Options ajusta o comportamento do interpretador.
+type Options struct {
+	// AllowShadowing permite redeclarar, no escopo global, nomes de funções
+	// built-in e reatribuir funções de bibliotecas incluídas.
+	AllowShadowing bool
+}
+
 func New() *Evaluator {
+	return NewWithOptions(Options{})
+}
+
+func NewWithOptions(options Options) *Evaluator {
 	return &Evaluator{
 		libraryManager: library.NewLibraryManager(),
+		options:        options,
 	}
 }
 
 type Evaluator struct{
 	libraryManager *library.LibraryManager
+	options        Options
 }
 
 func (e *Evaluator) Eval(node ast.Node) object.Object {
 	env := object.NewEnvironment()
+	if program, ok := node.(*ast.Program); ok {
+		env.WithScope(program.Scope)
+	}
 	return e.EvalWithEnv(node, env)
 }
 
@@ -46,9 +72,22 @@ func (e *Evaluator) EvalWithEnv(node ast.Node, env *object.Environment) object.O
 		if isError(val) {
 			return val
 		}
-		env.Set(node.Name.Value, val)
+		if err := e.checkVariableDeclaration(env, node.Name.Value); err != nil {
+			return err
+		}
+		if node.Constant {
+			env.SetConstant(node.Name.Value, val)
+		} else {
+			env.Set(node.Name.Value, val)
+		}
 		return val
 
+	case *ast.AssignStatement:
+		return e.evalAssignStatement(node, env)
+
+	case *ast.DestructureStatement:
+		return e.evalDestructureStatement(node, env)
+
 	case *ast.ReturnStatement:
 		val := e.EvalWithEnv(node.ReturnValue, env)
 		if isError(val) {
@@ -59,11 +98,26 @@ func (e *Evaluator) EvalWithEnv(node ast.Node, env *object.Environment) object.O
 	case *ast.ForStatement:
 		return e.evalForStatement(node, env)
 
+	case *ast.ForEachStatement:
+		return e.evalForEachStatement(node, env)
+
+	case *ast.RecordStatement:
+		return e.evalRecordStatement(node, env)
+
+	case *ast.ClassStatement:
+		return e.evalClassStatement(node, env)
+
+	case *ast.EnumStatement:
+		return e.evalEnumStatement(node, env)
+
 	case *ast.IncludeStatement:
 		return e.evalIncludeStatement(node, env)
 
 
 	case *ast.IntegerLiteral:
+		if node.Big != nil {
+			return object.NewInteger(node.Big)
+		}
 		return &object.Integer{Value: node.Value}
 
 	case *ast.FloatLiteral:
@@ -75,6 +129,12 @@ func (e *Evaluator) EvalWithEnv(node ast.Node, env *object.Environment) object.O
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
 
+	case *ast.NullLiteral:
+		return NULL
+
+	case *ast.SwitchExpression:
+		return e.evalSwitchExpression(node, env)
+
 	case *ast.PrefixExpression:
 		right := e.EvalWithEnv(node.Right, env)
 		if isError(right) {
@@ -83,11 +143,6 @@ func (e *Evaluator) EvalWithEnv(node ast.Node, env *object.Environment) object.O
 		return e.evalPrefixExpression(node.Operator, right)
 
 	case *ast.InfixExpression:
-
-		if e.needsMathLibrary(node.Operator) && !e.libraryManager.IsLibraryLoaded("smath") {
-			return newError("operações matemáticas avançadas requerem a biblioteca 'smath'. Execute: sovy install smath")
-		}
-
 		left := e.EvalWithEnv(node.Left, env)
 		if isError(left) {
 			return left
@@ -100,6 +155,9 @@ func (e *Evaluator) EvalWithEnv(node ast.Node, env *object.Environment) object.O
 
 		return e.evalInfixExpression(node.Operator, left, right)
 
+	case *ast.LogicalExpression:
+		return e.evalLogicalExpression(node, env)
+
 	case *ast.IfExpression:
 		return e.evalIfExpression(node, env)
 
@@ -109,11 +167,14 @@ func (e *Evaluator) EvalWithEnv(node ast.Node, env *object.Environment) object.O
 	case *ast.FunctionLiteral:
 		params := node.Parameters
 		body := node.Body
-		fn := &object.Function{Parameters: params, Env: env, Body: body}
+		fn := &object.Function{Parameters: params, ReturnType: node.ReturnType, Env: env, Body: body}
 
 
 		if node.Name != nil {
-			env.Set(node.Name.Value, fn)
+			fn.Name = node.Name.Value
+			if err := e.declare(env, fn.Name, fn); err != nil {
+				return err
+			}
 		}
 
 		return fn
@@ -124,12 +185,26 @@ func (e *Evaluator) EvalWithEnv(node ast.Node, env *object.Environment) object.O
 			return function
 		}
 
-		args := e.evalExpressions(node.Arguments, env)
-		if len(args) == 1 && isError(args[0]) {
-			return args[0]
+		args, named, err := e.evalCallArguments(node.Arguments, env)
+		if err != nil {
+			return err
+		}
+
+		if recordType, ok := function.(*object.RecordType); ok {
+			return e.instantiateRecord(recordType, args, named)
+		}
+		if class, ok := function.(*object.Class); ok {
+			return e.instantiateClass(class, args, named)
 		}
+		var ctx *object.CallContext
+		if _, ok := function.(*object.Builtin); ok {
+			ctx = e.callContext(env, node.Token)
+		}
+		return e.applyFunction(function, args, named, ctx)
 
-		return e.applyFunction(function, args)
+	case *ast.MemberExpression:
+		result, _ := e.evalChain(node, env)
+		return result
 
 	case *ast.ArrayLiteral:
 		elements := e.evalExpressions(node.Elements, env)
@@ -138,16 +213,27 @@ func (e *Evaluator) EvalWithEnv(node ast.Node, env *object.Environment) object.O
 		}
 		return &object.Array{Elements: elements}
 
-	case *ast.IndexExpression:
-		left := e.EvalWithEnv(node.Left, env)
-		if isError(left) {
-			return left
+	case *ast.SetLiteral:
+		elements := e.evalExpressions(node.Elements, env)
+		if len(elements) == 1 && isError(elements[0]) {
+			return elements[0]
 		}
-		index := e.EvalWithEnv(node.Index, env)
-		if isError(index) {
-			return index
+		return newSet(elements)
+
+	case *ast.TupleLiteral:
+		elements := e.evalExpressions(node.Elements, env)
+		if len(elements) == 1 && isError(elements[0]) {
+			return elements[0]
 		}
-		return e.evalIndexExpression(left, index)
+		return &object.Tuple{Elements: elements}
+
+	case *ast.IndexExpression:
+		result, _ := e.evalChain(node, env)
+		return result
+
+	case *ast.SliceExpression:
+		result, _ := e.evalChain(node, env)
+		return result
 
 	case *ast.HashLiteral:
 		return e.evalHashLiteral(node, env)
@@ -212,13 +298,52 @@ func (e *Evaluator) evalForStatement(node *ast.ForStatement, env *object.Environ
 		return newError("valor final do loop deve ser inteiro, recebido=%T", end)
 	}
 
+	if err := e.checkVariableDeclaration(env, node.Variable.Value); err != nil {
+		return err
+	}
+
 	var result object.Object
 
 	for i := startInt.Value; i <= endInt.Value; i++ {
+		// cada volta tem seu próprio escopo, para que closures criadas no
+		// corpo guardem o valor daquela volta
+		iterEnv := object.NewBlockEnvironment(env).WithScope(node.Body.Scope)
+		iterEnv.Set(node.Variable.Value, &object.Integer{Value: i})
+
+		result = e.EvalWithEnv(node.Body, iterEnv)
+
+		if result != nil {
+			rt := result.Type()
+			if rt == object.RETURN_OBJ || rt == object.ERROR_OBJ {
+				return result
+			}
+		}
+	}
+
+	return result
+}
+
+func (e *Evaluator) evalForEachStatement(node *ast.ForEachStatement, env *object.Environment) object.Object {
+	iterable := e.EvalWithEnv(node.Iterable, env)
+	if isError(iterable) {
+		return iterable
+	}
+
+	elements, err := iterate(iterable)
+	if err != nil {
+		return err
+	}
+	if err := e.checkVariableDeclaration(env, node.Variable.Value); err != nil {
+		return err
+	}
 
-		env.Set(node.Variable.Value, &object.Integer{Value: i})
+	var result object.Object
+
+	for _, element := range elements {
+		iterEnv := object.NewBlockEnvironment(env).WithScope(node.Body.Scope)
+		iterEnv.Set(node.Variable.Value, element)
 
-		result = e.EvalWithEnv(node.Body, env)
+		result = e.EvalWithEnv(node.Body, iterEnv)
 
 		if result != nil {
 			rt := result.Type()
@@ -231,12 +356,208 @@ func (e *Evaluator) evalForStatement(node *ast.ForStatement, env *object.Environ
 	return result
 }
 
+// iterate devolve os elementos percorridos por "para cada". Uma cópia é
+// feita antes do laço, então alterar a coleção dentro dele não afeta a
+// iteração em andamento.
+func iterate(obj object.Object) ([]object.Object, *object.Error) {
+	switch obj := obj.(type) {
+	case *object.Array:
+		elements := make([]object.Object, len(obj.Elements))
+		copy(elements, obj.Elements)
+		return elements, nil
+	case *object.Tuple:
+		elements := make([]object.Object, len(obj.Elements))
+		copy(elements, obj.Elements)
+		return elements, nil
+	case *object.Set:
+		return obj.Elements(), nil
+	case *object.Enum:
+		members := make([]object.Object, len(obj.Members))
+		for i, member := range obj.Members {
+			members[i] = member
+		}
+		return members, nil
+	case *object.Hash:
+		pairs := obj.Pairs()
+		keys := make([]object.Object, len(pairs))
+		for i, pair := range pairs {
+			keys[i] = pair.Key
+		}
+		return keys, nil
+	case *object.String:
+		elements := []object.Object{}
+		for _, r := range obj.Value {
+			elements = append(elements, &object.String{Value: string(r)})
+		}
+		return elements, nil
+	default:
+		return nil, newError("não é possível percorrer %s", obj.Type())
+	}
+}
+
+func (e *Evaluator) evalAssignStatement(node *ast.AssignStatement, env *object.Environment) object.Object {
+	val := e.EvalWithEnv(node.Value, env)
+	if isError(val) {
+		return val
+	}
+
+	return e.assign(node.Target, val, env)
+}
+
+// assign só é chamado depois que todo o lado direito foi avaliado, então
+// "a, b = b, a" troca os valores.
+func (e *Evaluator) assign(target ast.Expression, val object.Object, env *object.Environment) object.Object {
+	switch target := target.(type) {
+	case *ast.TupleLiteral:
+		values, err := unpack(val, len(target.Elements))
+		if err != nil {
+			return err
+		}
+		for i, element := range target.Elements {
+			if result := e.assign(element, values[i], env); isError(result) {
+				return result
+			}
+		}
+		return val
+
+	case *ast.MemberExpression:
+		obj := e.EvalWithEnv(target.Object, env)
+		if isError(obj) {
+			return obj
+		}
+		return e.evalMemberAssignment(obj, target.Property.Value, val)
+
+	case *ast.Identifier:
+		if target.Resolved {
+			found, constant := env.AssignAt(target.Depth, target.Slot, target.Value, val)
+			if constant {
+				return newError("não é possível alterar a constante %s", target.Value)
+			}
+			if found {
+				return val
+			}
+		}
+		if env.IsConstant(target.Value) {
+			return newError("não é possível alterar a constante %s", target.Value)
+		}
+		if !env.Assign(target.Value, val) {
+			return newError("identificador não encontrado: " + target.Value)
+		}
+		return val
+
+	case *ast.IndexExpression:
+		left := e.EvalWithEnv(target.Left, env)
+		if isError(left) {
+			return left
+		}
+		index := e.EvalWithEnv(target.Index, env)
+		if isError(index) {
+			return index
+		}
+		return e.evalIndexAssignment(left, index, val)
+
+	default:
+		return newError("não é possível atribuir a %s", target.String())
+	}
+}
+
+func unpack(val object.Object, count int) ([]object.Object, *object.Error) {
+	var values []object.Object
+	switch val := val.(type) {
+	case *object.Array:
+		values = val.Elements
+	case *object.Tuple:
+		values = val.Elements
+	default:
+		return nil, newError("não é possível desempacotar %s", val.Type())
+	}
+
+	if len(values) != count {
+		return nil, newError("esperados %d valores para desempacotar, recebidos %d", count, len(values))
+	}
+	return values, nil
+}
+
+func (e *Evaluator) evalDestructureStatement(node *ast.DestructureStatement, env *object.Environment) object.Object {
+	val := e.EvalWithEnv(node.Value, env)
+	if isError(val) {
+		return val
+	}
+
+	if !node.IsMap() {
+		values, err := unpack(val, len(node.Names))
+		if err != nil {
+			return err
+		}
+		for i, name := range node.Names {
+			if err := e.checkVariableDeclaration(env, name.Value); err != nil {
+				return err
+			}
+			env.Set(name.Value, values[i])
+		}
+		return val
+	}
+
+	for _, name := range node.Names {
+		var field object.Object
+		switch source := val.(type) {
+		case *object.Hash:
+			pair, ok := source.Get(&object.String{Value: name.Value})
+			if !ok {
+				return newError("chave não encontrada ao desestruturar: %s", name.Value)
+			}
+			field = pair.Value
+		case *object.Record, *object.Instance:
+			field = e.evalMemberExpression(source, name.Value)
+			if isError(field) {
+				return field
+			}
+		default:
+			return newError("não é possível desestruturar %s como mapa", val.Type())
+		}
+		if err := e.checkVariableDeclaration(env, name.Value); err != nil {
+			return err
+		}
+		env.Set(name.Value, field)
+	}
+
+	return val
+}
+
+func (e *Evaluator) evalIndexAssignment(left, index, val object.Object) object.Object {
+	switch left := left.(type) {
+	case *object.Array:
+		idx, ok := index.(*object.Integer)
+		if !ok {
+			return newError("índice de lista deve ser inteiro, recebido %s", index.Type())
+		}
+		i, ok := normalizeIndex(idx.Value, len(left.Elements))
+		if !ok {
+			return newError("índice fora do intervalo: %d (tamanho %d)", idx.Value, len(left.Elements))
+		}
+		left.Elements[i] = val
+		return val
+
+	case *object.Hash:
+		if !object.IsHashable(index) {
+			return invalidHashKeyError(index)
+		}
+		left.Set(index, val)
+		return val
+
+	default:
+		return newError("atribuição por índice não suportada: %s", left.Type())
+	}
+}
+
 func (e *Evaluator) evalPrefixExpression(operator string, right object.Object) object.Object {
 	switch operator {
 	case "!", "não", "nao":
 		return e.evalBangOperatorExpression(right)
 	case "-":
 		return e.evalMinusPrefixOperatorExpression(right)
+	case "~":
+		return e.evalBitwiseNotOperatorExpression(right)
 	default:
 		return newError("operador desconhecido: %s%s", operator, right.Type())
 	}
@@ -244,8 +565,34 @@ func (e *Evaluator) evalPrefixExpression(operator string, right object.Object) o
 
 func (e *Evaluator) evalInfixExpression(operator string, left, right object.Object) object.Object {
 	switch {
+	case operator == "é":
+		return nativeBoolToPyObject(object.Equal(left, right))
+	case operator == "em":
+		return e.evalMembershipExpression(left, right)
+	case left.Type() == object.SET_OBJ && right.Type() == object.SET_OBJ:
+		return e.evalSetInfixExpression(operator, left.(*object.Set), right.(*object.Set))
+	case isBitwiseOperator(operator) && !(isInteger(left) && isInteger(right)):
+		return newError("operador %s requer inteiros, recebido %s %s %s", operator, left.Type(), operator, right.Type())
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
 		return e.evalIntegerInfixExpression(operator, left, right)
+	case (left.Type() == object.DECIMAL_OBJ || right.Type() == object.DECIMAL_OBJ) && object.IsNumber(left) && object.IsNumber(right):
+		leftDec, err := object.ToDecimal(left)
+		if err != nil {
+			return newError(err.Error())
+		}
+		rightDec, err := object.ToDecimal(right)
+		if err != nil {
+			return newError(err.Error())
+		}
+		return e.evalDecimalInfixExpression(operator, leftDec, rightDec)
+	case isInteger(left) && isInteger(right):
+		return e.evalBigIntegerInfixExpression(operator, toBigInt(left), toBigInt(right))
+	case left.Type() == object.BIG_INTEGER_OBJ && right.Type() == object.FLOAT_OBJ:
+		leftFloat, _ := new(big.Float).SetInt(left.(*object.BigInteger).Value).Float64()
+		return e.evalFloatInfixExpression(operator, &object.Float{Value: leftFloat}, right)
+	case left.Type() == object.FLOAT_OBJ && right.Type() == object.BIG_INTEGER_OBJ:
+		rightFloat, _ := new(big.Float).SetInt(right.(*object.BigInteger).Value).Float64()
+		return e.evalFloatInfixExpression(operator, left, &object.Float{Value: rightFloat})
 	case left.Type() == object.FLOAT_OBJ && right.Type() == object.FLOAT_OBJ:
 		return e.evalFloatInfixExpression(operator, left, right)
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.FLOAT_OBJ:
@@ -257,36 +604,194 @@ func (e *Evaluator) evalInfixExpression(operator string, left, right object.Obje
 	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
 		return e.evalStringInfixExpression(operator, left, right)
 	case operator == "==":
-		return nativeBoolToPyObject(left == right)
+		return nativeBoolToPyObject(object.Equal(left, right))
 	case operator == "!=":
-		return nativeBoolToPyObject(left != right)
-	case operator == "e":
-		return nativeBoolToPyObject(isTruthy(left) && isTruthy(right))
-	case operator == "ou":
-		return nativeBoolToPyObject(isTruthy(left) || isTruthy(right))
+		return nativeBoolToPyObject(!object.Equal(left, right))
+	default:
+		return newError("operador desconhecido: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func (e *Evaluator) evalMembershipExpression(element, collection object.Object) object.Object {
+	switch collection := collection.(type) {
+	case *object.Set:
+		return nativeBoolToPyObject(object.IsHashable(element) && collection.Contains(element))
+	case *object.Hash:
+		if !object.IsHashable(element) {
+			return FALSE
+		}
+		_, ok := collection.Get(element)
+		return nativeBoolToPyObject(ok)
+	case *object.Array:
+		return nativeBoolToPyObject(containsElement(collection.Elements, element))
+	case *object.Tuple:
+		return nativeBoolToPyObject(containsElement(collection.Elements, element))
+	case *object.String:
+		sub, ok := element.(*object.String)
+		if !ok {
+			return newError("operador em com texto requer texto à esquerda, recebido %s", element.Type())
+		}
+		return nativeBoolToPyObject(strings.Contains(collection.Value, sub.Value))
+	default:
+		return newError("operador em não suportado: %s", collection.Type())
+	}
+}
+
+func containsElement(elements []object.Object, element object.Object) bool {
+	for _, e := range elements {
+		if object.Equal(e, element) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Evaluator) evalSetInfixExpression(operator string, left, right *object.Set) object.Object {
+	switch operator {
+	case "|":
+		return setUnion(left, right)
+	case "&":
+		return setIntersection(left, right)
+	case "-":
+		return setDifference(left, right)
+	case "^":
+		return setUnion(setDifference(left, right), setDifference(right, left))
+	case "==":
+		return nativeBoolToPyObject(object.Equal(left, right))
+	case "!=":
+		return nativeBoolToPyObject(!object.Equal(left, right))
 	default:
 		return newError("operador desconhecido: %s %s %s", left.Type(), operator, right.Type())
 	}
 }
 
+// newSet assume que os elementos já foram avaliados; o primeiro valor que não
+// pode ser chave vira o erro devolvido.
+func newSet(elements []object.Object) object.Object {
+	set := object.NewSet()
+	for _, element := range elements {
+		if !object.IsHashable(element) {
+			return invalidSetElementError(element)
+		}
+		set.Add(element)
+	}
+	return set
+}
+
+func invalidSetElementError(element object.Object) *object.Error {
+	if element.Type() == object.ARRAY_OBJ {
+		return newError("elemento de conjunto inválido: listas podem ser alteradas; use uma tupla, como tupla(lista)")
+	}
+	return newError("elemento de conjunto inválido: %s", element.Type())
+}
+
+func setUnion(left, right *object.Set) *object.Set {
+	result := object.NewSet()
+	for _, element := range left.Elements() {
+		result.Add(element)
+	}
+	for _, element := range right.Elements() {
+		result.Add(element)
+	}
+	return result
+}
+
+func setIntersection(left, right *object.Set) *object.Set {
+	result := object.NewSet()
+	for _, element := range left.Elements() {
+		if right.Contains(element) {
+			result.Add(element)
+		}
+	}
+	return result
+}
+
+func setDifference(left, right *object.Set) *object.Set {
+	result := object.NewSet()
+	for _, element := range left.Elements() {
+		if !right.Contains(element) {
+			result.Add(element)
+		}
+	}
+	return result
+}
+
+func (e *Evaluator) evalLogicalExpression(node *ast.LogicalExpression, env *object.Environment) object.Object {
+	left := e.EvalWithEnv(node.Left, env)
+	if isError(left) {
+		return left
+	}
+
+	switch node.Operator {
+	case "e":
+		if !isTruthy(left) {
+			return FALSE
+		}
+	case "ou":
+		if isTruthy(left) {
+			return TRUE
+		}
+	case "??":
+		if left != NULL {
+			return left
+		}
+		return e.EvalWithEnv(node.Right, env)
+	default:
+		return newError("operador lógico desconhecido: %s", node.Operator)
+	}
+
+	right := e.EvalWithEnv(node.Right, env)
+	if isError(right) {
+		return right
+	}
+
+	return nativeBoolToPyObject(isTruthy(right))
+}
+
 func (e *Evaluator) evalIntegerInfixExpression(operator string, left, right object.Object) object.Object {
 	leftVal := left.(*object.Integer).Value
 	rightVal := right.(*object.Integer).Value
 
 	switch operator {
 	case "+":
-		return &object.Integer{Value: leftVal + rightVal}
+		sum := leftVal + rightVal
+		if (leftVal^sum)&(rightVal^sum) < 0 {
+			return e.evalBigIntegerInfixExpression(operator, big.NewInt(leftVal), big.NewInt(rightVal))
+		}
+		return &object.Integer{Value: sum}
 	case "-":
-		return &object.Integer{Value: leftVal - rightVal}
+		diff := leftVal - rightVal
+		if (leftVal^rightVal)&(leftVal^diff) < 0 {
+			return e.evalBigIntegerInfixExpression(operator, big.NewInt(leftVal), big.NewInt(rightVal))
+		}
+		return &object.Integer{Value: diff}
 	case "*":
-		return &object.Integer{Value: leftVal * rightVal}
+		if leftVal != 0 {
+			product := leftVal * rightVal
+			if product/leftVal != rightVal || (leftVal == -1 && rightVal == math.MinInt64) {
+				return e.evalBigIntegerInfixExpression(operator, big.NewInt(leftVal), big.NewInt(rightVal))
+			}
+			return &object.Integer{Value: product}
+		}
+		return &object.Integer{Value: 0}
 	case "/":
 		if rightVal == 0 {
 			return newError("divisão por zero")
 		}
 		return &object.Float{Value: float64(leftVal) / float64(rightVal)}
 	case "%":
+		if rightVal == 0 {
+			return newError("divisão por zero")
+		}
 		return &object.Integer{Value: leftVal % rightVal}
+	case "&":
+		return &object.Integer{Value: leftVal & rightVal}
+	case "|":
+		return &object.Integer{Value: leftVal | rightVal}
+	case "^":
+		return &object.Integer{Value: leftVal ^ rightVal}
+	case "<<", ">>":
+		return e.evalBigIntegerInfixExpression(operator, big.NewInt(leftVal), big.NewInt(rightVal))
 	case "<":
 		return nativeBoolToPyObject(leftVal < rightVal)
 	case ">":
@@ -304,6 +809,96 @@ func (e *Evaluator) evalIntegerInfixExpression(operator string, left, right obje
 	}
 }
 
+func (e *Evaluator) evalBigIntegerInfixExpression(operator string, leftVal, rightVal *big.Int) object.Object {
+	switch operator {
+	case "+":
+		return object.NewInteger(new(big.Int).Add(leftVal, rightVal))
+	case "-":
+		return object.NewInteger(new(big.Int).Sub(leftVal, rightVal))
+	case "*":
+		return object.NewInteger(new(big.Int).Mul(leftVal, rightVal))
+	case "/":
+		if rightVal.Sign() == 0 {
+			return newError("divisão por zero")
+		}
+		quotient, _ := new(big.Float).Quo(new(big.Float).SetInt(leftVal), new(big.Float).SetInt(rightVal)).Float64()
+		return &object.Float{Value: quotient}
+	case "%":
+		if rightVal.Sign() == 0 {
+			return newError("divisão por zero")
+		}
+		return object.NewInteger(new(big.Int).Rem(leftVal, rightVal))
+	case "&":
+		return object.NewInteger(new(big.Int).And(leftVal, rightVal))
+	case "|":
+		return object.NewInteger(new(big.Int).Or(leftVal, rightVal))
+	case "^":
+		return object.NewInteger(new(big.Int).Xor(leftVal, rightVal))
+	case "<<", ">>":
+		if rightVal.Sign() < 0 {
+			return newError("deslocamento negativo: %s", rightVal.String())
+		}
+		if !rightVal.IsUint64() || rightVal.Uint64() > maxShift {
+			return newError("deslocamento muito grande: %s", rightVal.String())
+		}
+		if operator == "<<" {
+			return object.NewInteger(new(big.Int).Lsh(leftVal, uint(rightVal.Uint64())))
+		}
+		return object.NewInteger(new(big.Int).Rsh(leftVal, uint(rightVal.Uint64())))
+	case "<":
+		return nativeBoolToPyObject(leftVal.Cmp(rightVal) < 0)
+	case ">":
+		return nativeBoolToPyObject(leftVal.Cmp(rightVal) > 0)
+	case "<=":
+		return nativeBoolToPyObject(leftVal.Cmp(rightVal) <= 0)
+	case ">=":
+		return nativeBoolToPyObject(leftVal.Cmp(rightVal) >= 0)
+	case "==":
+		return nativeBoolToPyObject(leftVal.Cmp(rightVal) == 0)
+	case "!=":
+		return nativeBoolToPyObject(leftVal.Cmp(rightVal) != 0)
+	default:
+		return newError("operador desconhecido: %s", operator)
+	}
+}
+
+func (e *Evaluator) evalDecimalInfixExpression(operator string, leftVal, rightVal decimal.Decimal) object.Object {
+	switch operator {
+	case "+":
+		return &object.Decimal{Value: leftVal.Add(rightVal)}
+	case "-":
+		return &object.Decimal{Value: leftVal.Sub(rightVal)}
+	case "*":
+		return &object.Decimal{Value: leftVal.Mul(rightVal)}
+	case "/":
+		quotient, err := leftVal.Div(rightVal, decimal.HalfEven)
+		if err != nil {
+			return newError(err.Error())
+		}
+		return &object.Decimal{Value: quotient}
+	case "%":
+		remainder, err := leftVal.Rem(rightVal)
+		if err != nil {
+			return newError(err.Error())
+		}
+		return &object.Decimal{Value: remainder}
+	case "<":
+		return nativeBoolToPyObject(leftVal.Cmp(rightVal) < 0)
+	case ">":
+		return nativeBoolToPyObject(leftVal.Cmp(rightVal) > 0)
+	case "<=":
+		return nativeBoolToPyObject(leftVal.Cmp(rightVal) <= 0)
+	case ">=":
+		return nativeBoolToPyObject(leftVal.Cmp(rightVal) >= 0)
+	case "==":
+		return nativeBoolToPyObject(leftVal.Cmp(rightVal) == 0)
+	case "!=":
+		return nativeBoolToPyObject(leftVal.Cmp(rightVal) != 0)
+	default:
+		return newError("operador desconhecido: %s", operator)
+	}
+}
+
 func (e *Evaluator) evalFloatInfixExpression(operator string, left, right object.Object) object.Object {
 	leftVal := left.(*object.Float).Value
 	rightVal := right.(*object.Float).Value
@@ -320,6 +915,11 @@ func (e *Evaluator) evalFloatInfixExpression(operator string, left, right object
 			return newError("divisão por zero")
 		}
 		return &object.Float{Value: leftVal / rightVal}
+	case "%":
+		if rightVal == 0 {
+			return newError("divisão por zero")
+		}
+		return &object.Float{Value: math.Mod(leftVal, rightVal)}
 	case "<":
 		return nativeBoolToPyObject(leftVal < rightVal)
 	case ">":
@@ -344,6 +944,14 @@ func (e *Evaluator) evalStringInfixExpression(operator string, left, right objec
 	switch operator {
 	case "+":
 		return &object.String{Value: leftVal + rightVal}
+	case "<":
+		return nativeBoolToPyObject(collate.Compare(leftVal, rightVal) < 0)
+	case ">":
+		return nativeBoolToPyObject(collate.Compare(leftVal, rightVal) > 0)
+	case "<=":
+		return nativeBoolToPyObject(collate.Compare(leftVal, rightVal) <= 0)
+	case ">=":
+		return nativeBoolToPyObject(collate.Compare(leftVal, rightVal) >= 0)
 	case "==":
 		return nativeBoolToPyObject(leftVal == rightVal)
 	case "!=":
@@ -369,7 +977,14 @@ func (e *Evaluator) evalBangOperatorExpression(right object.Object) object.Objec
 func (e *Evaluator) evalMinusPrefixOperatorExpression(right object.Object) object.Object {
 	switch right := right.(type) {
 	case *object.Integer:
+		if right.Value == math.MinInt64 {
+			return object.NewInteger(new(big.Int).Neg(big.NewInt(right.Value)))
+		}
 		return &object.Integer{Value: -right.Value}
+	case *object.BigInteger:
+		return object.NewInteger(new(big.Int).Neg(right.Value))
+	case *object.Decimal:
+		return &object.Decimal{Value: right.Value.Neg()}
 	case *object.Float:
 		return &object.Float{Value: -right.Value}
 	default:
@@ -377,6 +992,17 @@ func (e *Evaluator) evalMinusPrefixOperatorExpression(right object.Object) objec
 	}
 }
 
+func (e *Evaluator) evalBitwiseNotOperatorExpression(right object.Object) object.Object {
+	switch right := right.(type) {
+	case *object.Integer:
+		return &object.Integer{Value: ^right.Value}
+	case *object.BigInteger:
+		return object.NewInteger(new(big.Int).Not(right.Value))
+	default:
+		return newError("operador ~ requer inteiro, recebido %s", right.Type())
+	}
+}
+
 func (e *Evaluator) evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
 	condition := e.EvalWithEnv(ie.Condition, env)
 	if isError(condition) {
@@ -384,26 +1010,215 @@ func (e *Evaluator) evalIfExpression(ie *ast.IfExpression, env *object.Environme
 	}
 
 	if isTruthy(condition) {
-		return e.EvalWithEnv(ie.Consequence, env)
+		return e.EvalWithEnv(ie.Consequence, object.NewBlockEnvironment(env).WithScope(ie.Consequence.Scope))
 	} else if ie.Alternative != nil {
-		return e.EvalWithEnv(ie.Alternative, env)
+		return e.EvalWithEnv(ie.Alternative, object.NewBlockEnvironment(env).WithScope(ie.Alternative.Scope))
 	} else {
 		return NULL
 	}
 }
 
+func (e *Evaluator) evalSwitchExpression(node *ast.SwitchExpression, env *object.Environment) object.Object {
+	subject := e.EvalWithEnv(node.Subject, env)
+	if isError(subject) {
+		return subject
+	}
+
+	if member, ok := subject.(*object.EnumMember); ok && node.Default == nil {
+		if err := e.checkEnumCoverage(node, member.Enum, env); err != nil {
+			return err
+		}
+	}
+
+	for _, switchCase := range node.Cases {
+		for _, pattern := range switchCase.Patterns {
+			caseEnv := object.NewBlockEnvironment(env).WithScope(switchCase.Body.Scope)
+
+			matched, err := e.matchPattern(pattern, subject, caseEnv)
+			if err != nil {
+				return err
+			}
+			if !matched {
+				continue
+			}
+
+			if switchCase.Guard != nil {
+				guard := e.EvalWithEnv(switchCase.Guard, caseEnv)
+				if isError(guard) {
+					return guard
+				}
+				if !isTruthy(guard) {
+					continue
+				}
+			}
+
+			return e.EvalWithEnv(switchCase.Body, caseEnv)
+		}
+	}
+
+	if node.Default != nil {
+		return e.EvalWithEnv(node.Default, object.NewBlockEnvironment(env).WithScope(node.Default.Scope))
+	}
+
+	return NULL
+}
+
+// matchPattern compara o valor com um padrão de caso, registrando em env os
+// nomes capturados.
+func (e *Evaluator) matchPattern(pattern ast.Expression, value object.Object, env *object.Environment) (bool, object.Object) {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		if pattern.Value != "_" {
+			env.Set(pattern.Value, value)
+		}
+		return true, nil
+
+	case *ast.RangePattern:
+		low := e.EvalWithEnv(pattern.Low, env)
+		if isError(low) {
+			return false, low
+		}
+		high := e.EvalWithEnv(pattern.High, env)
+		if isError(high) {
+			return false, high
+		}
+		return inRange(value, low, high), nil
+
+	case *ast.ArrayLiteral:
+		array, ok := value.(*object.Array)
+		if !ok {
+			return false, nil
+		}
+		return e.matchElements(pattern.Elements, array.Elements, env)
+
+	case *ast.TupleLiteral:
+		tuple, ok := value.(*object.Tuple)
+		if !ok {
+			return false, nil
+		}
+		return e.matchElements(pattern.Elements, tuple.Elements, env)
+
+	case *ast.HashLiteral:
+		hash, ok := value.(*object.Hash)
+		if !ok {
+			return false, nil
+		}
+		for _, pair := range pattern.Pairs {
+			key := e.EvalWithEnv(pair.Key, env)
+			if isError(key) {
+				return false, key
+			}
+			if !object.IsHashable(key) {
+				return false, invalidHashKeyError(key)
+			}
+			found, ok := hash.Get(key)
+			if !ok {
+				return false, nil
+			}
+			matched, err := e.matchPattern(pair.Value, found.Value, env)
+			if err != nil || !matched {
+				return false, err
+			}
+		}
+		return true, nil
+
+	default:
+		expected := e.EvalWithEnv(pattern, env)
+		if isError(expected) {
+			return false, expected
+		}
+		return object.Equal(expected, value), nil
+	}
+}
+
+func (e *Evaluator) matchElements(patterns []ast.Expression, values []object.Object, env *object.Environment) (bool, object.Object) {
+	if len(patterns) != len(values) {
+		return false, nil
+	}
+	for i, pattern := range patterns {
+		matched, err := e.matchPattern(pattern, values[i], env)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func inRange(value, low, high object.Object) bool {
+	if cmp, ok := object.CompareNumbers(value, low); ok {
+		upper, ok := object.CompareNumbers(value, high)
+		return ok && cmp >= 0 && upper <= 0
+	}
+
+	str, ok := value.(*object.String)
+	lowStr, lowOk := low.(*object.String)
+	highStr, highOk := high.(*object.String)
+	if !ok || !lowOk || !highOk {
+		return false
+	}
+	return collate.Compare(str.Value, lowStr.Value) >= 0 && collate.Compare(str.Value, highStr.Value) <= 0
+}
+
+// checkEnumCoverage exige que uma escolha sobre uma enumeração sem padrão
+// trate todos os membros. Casos com guarda não contam, pois podem falhar.
+func (e *Evaluator) checkEnumCoverage(node *ast.SwitchExpression, enum *object.Enum, env *object.Environment) object.Object {
+	covered := map[*object.EnumMember]bool{}
+
+	for _, switchCase := range node.Cases {
+		if switchCase.Guard != nil {
+			continue
+		}
+		// os padrões foram resolvidos no quadro do caso
+		caseEnv := object.NewBlockEnvironment(env).WithScope(switchCase.Body.Scope)
+		for _, pattern := range switchCase.Patterns {
+			if _, ok := pattern.(*ast.Identifier); ok {
+				return nil
+			}
+			if _, ok := pattern.(*ast.MemberExpression); !ok {
+				continue
+			}
+			value := e.EvalWithEnv(pattern, caseEnv)
+			if isError(value) {
+				return value
+			}
+			if member, ok := value.(*object.EnumMember); ok && member.Enum == enum {
+				covered[member] = true
+			}
+		}
+	}
+
+	missing := []string{}
+	for _, member := range enum.Members {
+		if !covered[member] {
+			missing = append(missing, member.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return newError("escolha não cobre todos os membros de %s: faltam %s", enum.Name, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
 func (e *Evaluator) evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
 
-	if builtin, ok := builtins[node.Value]; ok {
-		return builtin
+	// nomes declarados no programa, como o parâmetro em "...resto", têm
+	// prioridade sobre as funções built-in de mesmo nome
+	if node.Resolved {
+		if val, ok := env.GetAt(node.Depth, node.Slot, node.Value); ok {
+			return val
+		}
+	} else if !node.Builtin {
+		if val, ok := env.Get(node.Value); ok {
+			return val
+		}
 	}
 
-	val, ok := env.Get(node.Value)
-	if !ok {
-		return newError("identificador não encontrado: " + node.Value)
+	if builtin, ok := builtins[node.Value]; ok {
+		return builtin
 	}
 
-	return val
+	return newError("identificador não encontrado: " + node.Value)
 }
 
 func (e *Evaluator) evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
@@ -420,29 +1235,176 @@ func (e *Evaluator) evalExpressions(exps []ast.Expression, env *object.Environme
 	return result
 }
 
-func (e *Evaluator) applyFunction(fn object.Object, args []object.Object) object.Object {
+type namedArgument struct {
+	name  string
+	value object.Object
+}
+
+func (e *Evaluator) evalCallArguments(exps []ast.Expression, env *object.Environment) ([]object.Object, []namedArgument, object.Object) {
+	var args []object.Object
+	var named []namedArgument
+
+	for _, exp := range exps {
+		if arg, ok := exp.(*ast.NamedArgument); ok {
+			for _, other := range named {
+				if other.name == arg.Name.Value {
+					return nil, nil, newError("argumento nomeado repetido: %s", arg.Name.Value)
+				}
+			}
+			value := e.EvalWithEnv(arg.Value, env)
+			if isError(value) {
+				return nil, nil, value
+			}
+			named = append(named, namedArgument{name: arg.Name.Value, value: value})
+			continue
+		}
+
+		value := e.EvalWithEnv(exp, env)
+		if isError(value) {
+			return nil, nil, value
+		}
+		args = append(args, value)
+	}
+
+	return args, named, nil
+}
+
+// callContext monta o contexto entregue a uma função built-in chamada na
+// posição tok. Callbacks invocados por ela recebem o mesmo contexto.
+func (e *Evaluator) callContext(env *object.Environment, tok token.Token) *object.CallContext {
+	ctx := &object.CallContext{Env: env, Line: tok.Line, Column: tok.Column}
+	ctx.Call = func(fn object.Object, args ...object.Object) object.Object {
+		return e.applyFunction(fn, args, nil, ctx)
+	}
+	return ctx
+}
+
+// applyFunction chama fn. ctx só é usado por funções built-in e pode ser nil
+// quando fn certamente não é uma.
+func (e *Evaluator) applyFunction(fn object.Object, args []object.Object, named []namedArgument, ctx *object.CallContext) object.Object {
 	switch fn := fn.(type) {
 	case *object.Function:
-		extendedEnv := e.extendFunctionEnv(fn, args)
+		extendedEnv, err := e.extendFunctionEnv(fn, args, named)
+		if err != nil {
+			return err
+		}
 		evaluated := e.EvalWithEnv(fn.Body, extendedEnv)
-		return e.unwrapReturnValue(evaluated)
+		result := e.unwrapReturnValue(evaluated)
+		if result == nil {
+			result = NULL
+		}
+		if fn.ReturnType != "" && !isError(result) && !typeMatches(fn.ReturnType, result) {
+			return newError("%s: deve retornar %s, retornou %s", functionLabel(fn), fn.ReturnType, typeName(result))
+		}
+		return result
 
 	case *object.Builtin:
-		return fn.Fn(args...)
+		if len(named) > 0 {
+			return newError("argumentos nomeados não são aceitos por funções built-in")
+		}
+		return fn.Fn(ctx, args...)
+
+	case *object.BoundMethod:
+		methodEnv := object.NewEnclosedEnvironment(fn.Method.Env).WithScope(ast.MethodScope)
+		methodEnv.Set("este", fn.Receiver)
+		if fn.Owner.Superclass != nil {
+			methodEnv.Set("super", &object.Super{Receiver: fn.Receiver, Class: fn.Owner.Superclass})
+		}
+		method := &object.Function{
+			Name:       fn.Owner.Name + "." + fn.Name,
+			Parameters: fn.Method.Parameters,
+			ReturnType: fn.Method.ReturnType,
+			Body:       fn.Method.Body,
+			Env:        methodEnv,
+		}
+		return e.applyFunction(method, args, named, ctx)
 
 	default:
-		return newError("não é uma função: %T", fn)
+		return newError("não é uma função: %s", fn.Type())
 	}
 }
 
-func (e *Evaluator) extendFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
-	env := object.NewEnclosedEnvironment(fn.Env)
+// extendFunctionEnv liga os argumentos aos parâmetros: primeiro os
+// posicionais, depois os nomeados; o que faltar recebe o valor padrão,
+// avaliado no novo ambiente para poder usar os parâmetros anteriores.
+func (e *Evaluator) extendFunctionEnv(fn *object.Function, args []object.Object, named []namedArgument) (*object.Environment, *object.Error) {
+	env := object.NewEnclosedEnvironment(fn.Env).WithScope(fn.Body.Scope)
+
+	params := fn.Parameters
+	var variadic *ast.Parameter
+	if len(params) > 0 && params[len(params)-1].Variadic {
+		variadic = params[len(params)-1]
+		params = params[:len(params)-1]
+	}
+
+	if len(args) > len(params) && variadic == nil {
+		return nil, newError("%s: número errado de argumentos. esperado no máximo %d, recebido %d", functionLabel(fn), len(params), len(args))
+	}
+
+	values := make([]object.Object, len(params))
+	copy(values, args)
+
+	for _, arg := range named {
+		idx := -1
+		for i, param := range params {
+			if param.Name.Value == arg.name {
+				idx = i
+			}
+		}
+		if idx < 0 {
+			return nil, newError("%s: parâmetro desconhecido: %s", functionLabel(fn), arg.name)
+		}
+		if values[idx] != nil {
+			return nil, newError("%s: argumento %s informado mais de uma vez", functionLabel(fn), arg.name)
+		}
+		values[idx] = arg.value
+	}
+
+	for i, param := range params {
+		if values[i] == nil {
+			if param.Default == nil {
+				return nil, newError("%s: argumento obrigatório ausente: %s", functionLabel(fn), param.Name.Value)
+			}
+			value := e.EvalWithEnv(param.Default, env)
+			if isError(value) {
+				return nil, value.(*object.Error)
+			}
+			values[i] = value
+		}
+		if err := checkParameterType(fn, param, values[i]); err != nil {
+			return nil, err
+		}
+		env.Set(param.Name.Value, values[i])
+	}
+
+	if variadic != nil {
+		rest := []object.Object{}
+		if len(args) > len(params) {
+			rest = append(rest, args[len(params):]...)
+		}
+		for _, value := range rest {
+			if err := checkParameterType(fn, variadic, value); err != nil {
+				return nil, err
+			}
+		}
+		env.Set(variadic.Name.Value, &object.Array{Elements: rest})
+	}
+
+	return env, nil
+}
 
-	for paramIdx, param := range fn.Parameters {
-		env.Set(param.Value, args[paramIdx])
+func checkParameterType(fn *object.Function, param *ast.Parameter, val object.Object) *object.Error {
+	if param.Type == "" || typeMatches(param.Type, val) {
+		return nil
 	}
+	return newError("%s: parâmetro %s deve ser %s, recebido %s", functionLabel(fn), param.Name.Value, param.Type, typeName(val))
+}
 
-	return env
+func functionLabel(fn *object.Function) string {
+	if fn.Name == "" {
+		return "função anônima"
+	}
+	return fn.Name
 }
 
 func (e *Evaluator) unwrapReturnValue(obj object.Object) object.Object {
@@ -456,6 +1418,10 @@ func (e *Evaluator) evalIndexExpression(left, index object.Object) object.Object
 	switch {
 	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
 		return e.evalArrayIndexExpression(left, index)
+	case left.Type() == object.TUPLE_OBJ && index.Type() == object.INTEGER_OBJ:
+		return e.evalTupleIndexExpression(left, index)
+	case left.Type() == object.STRING_OBJ && index.Type() == object.INTEGER_OBJ:
+		return e.evalStringIndexExpression(left, index)
 	case left.Type() == object.HASH_OBJ:
 		return e.evalHashIndexExpression(left, index)
 	default:
@@ -465,51 +1431,233 @@ func (e *Evaluator) evalIndexExpression(left, index object.Object) object.Object
 
 func (e *Evaluator) evalArrayIndexExpression(array, index object.Object) object.Object {
 	arrayObject := array.(*object.Array)
-	idx := index.(*object.Integer).Value
-	max := int64(len(arrayObject.Elements) - 1)
-
-	if idx < 0 || idx > max {
+	idx, ok := normalizeIndex(index.(*object.Integer).Value, len(arrayObject.Elements))
+	if !ok {
 		return NULL
 	}
 
 	return arrayObject.Elements[idx]
 }
 
+func (e *Evaluator) evalTupleIndexExpression(tuple, index object.Object) object.Object {
+	tupleObject := tuple.(*object.Tuple)
+	idx, ok := normalizeIndex(index.(*object.Integer).Value, len(tupleObject.Elements))
+	if !ok {
+		return NULL
+	}
+
+	return tupleObject.Elements[idx]
+}
+
+func (e *Evaluator) evalStringIndexExpression(str, index object.Object) object.Object {
+	runes := []rune(str.(*object.String).Value)
+	idx, ok := normalizeIndex(index.(*object.Integer).Value, len(runes))
+	if !ok {
+		return NULL
+	}
+
+	return &object.String{Value: string(runes[idx])}
+}
+
+// evalChain avalia um acesso por índice, fatia ou membro. Quando um ?[ da
+// cadeia encontra nulo, o restante dela é pulado: com n nulo, n?["a"]["b"]
+// resulta em nulo. O segundo retorno indica que a cadeia foi interrompida.
+func (e *Evaluator) evalChain(node ast.Expression, env *object.Environment) (object.Object, bool) {
+	switch node := node.(type) {
+	case *ast.IndexExpression:
+		left, skipped := e.evalChain(node.Left, env)
+		if skipped || isError(left) {
+			return left, skipped
+		}
+		if node.Optional && left == NULL {
+			return NULL, true
+		}
+		index := e.EvalWithEnv(node.Index, env)
+		if isError(index) {
+			return index, false
+		}
+		return e.evalIndexExpression(left, index), false
+
+	case *ast.SliceExpression:
+		left, skipped := e.evalChain(node.Left, env)
+		if skipped || isError(left) {
+			return left, skipped
+		}
+		if node.Optional && left == NULL {
+			return NULL, true
+		}
+		return e.evalSliceExpression(node, left, env), false
+
+	case *ast.MemberExpression:
+		obj, skipped := e.evalChain(node.Object, env)
+		if skipped || isError(obj) {
+			return obj, skipped
+		}
+		return e.evalMemberExpression(obj, node.Property.Value), false
+
+	default:
+		return e.EvalWithEnv(node, env), false
+	}
+}
+
+func (e *Evaluator) evalSliceExpression(node *ast.SliceExpression, left object.Object, env *object.Environment) object.Object {
+
+	var bounds [3]*int64
+	for i, exp := range []ast.Expression{node.Start, node.End, node.Step} {
+		if exp == nil {
+			continue
+		}
+		val := e.EvalWithEnv(exp, env)
+		if isError(val) {
+			return val
+		}
+		if val == NULL {
+			continue
+		}
+		integer, ok := val.(*object.Integer)
+		if !ok {
+			return newError("limites de fatia devem ser inteiros, recebido %s", val.Type())
+		}
+		bounds[i] = &integer.Value
+	}
+
+	switch left := left.(type) {
+	case *object.Array:
+		indices, err := sliceIndices(len(left.Elements), bounds[0], bounds[1], bounds[2])
+		if err != nil {
+			return err
+		}
+		elements := make([]object.Object, 0, len(indices))
+		for _, i := range indices {
+			elements = append(elements, left.Elements[i])
+		}
+		return &object.Array{Elements: elements}
+
+	case *object.Tuple:
+		indices, err := sliceIndices(len(left.Elements), bounds[0], bounds[1], bounds[2])
+		if err != nil {
+			return err
+		}
+		elements := make([]object.Object, 0, len(indices))
+		for _, i := range indices {
+			elements = append(elements, left.Elements[i])
+		}
+		return &object.Tuple{Elements: elements}
+
+	case *object.String:
+		runes := []rune(left.Value)
+		indices, err := sliceIndices(len(runes), bounds[0], bounds[1], bounds[2])
+		if err != nil {
+			return err
+		}
+		result := make([]rune, 0, len(indices))
+		for _, i := range indices {
+			result = append(result, runes[i])
+		}
+		return &object.String{Value: string(result)}
+
+	default:
+		return newError("fatiamento não suportado: %s", left.Type())
+	}
+}
+
+// normalizeIndex converte índices negativos, que contam a partir do fim, e
+// informa se o resultado está dentro dos limites.
+func normalizeIndex(idx int64, length int) (int64, bool) {
+	if idx < 0 {
+		idx += int64(length)
+	}
+	return idx, idx >= 0 && idx < int64(length)
+}
+
+// sliceIndices segue as regras de fatiamento do Python: limites fora do
+// intervalo são ajustados em vez de gerar erro e um passo negativo percorre a
+// sequência de trás para frente.
+func sliceIndices(length int, start, end, step *int64) ([]int, *object.Error) {
+	n := int64(length)
+
+	stepVal := int64(1)
+	if step != nil {
+		stepVal = *step
+	}
+	if stepVal == 0 {
+		return nil, newError("passo da fatia não pode ser zero")
+	}
+	// Um passo maior que a lista só pega o primeiro elemento; limitá-lo
+	// evita que i transborde no laço.
+	if stepVal > n {
+		stepVal = n + 1
+	} else if stepVal < -n {
+		stepVal = -n - 1
+	}
+
+	clamp := func(bound *int64, def, lower, upper int64) int64 {
+		if bound == nil {
+			return def
+		}
+		v := *bound
+		if v < 0 {
+			v += n
+		}
+		if v < lower {
+			return lower
+		}
+		if v > upper {
+			return upper
+		}
+		return v
+	}
+
+	var indices []int
+	if stepVal > 0 {
+		from := clamp(start, 0, 0, n)
+		to := clamp(end, n, 0, n)
+		for i := from; i < to; i += stepVal {
+			indices = append(indices, int(i))
+		}
+	} else {
+		from := clamp(start, n-1, -1, n-1)
+		to := clamp(end, -1, -1, n-1)
+		for i := from; i > to; i += stepVal {
+			indices = append(indices, int(i))
+		}
+	}
+
+	return indices, nil
+}
+
 func (e *Evaluator) evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
-	pairs := make(map[object.HashKey]object.HashPair)
+	hash := object.NewHash()
 
-	for keyNode, valueNode := range node.Pairs {
-		key := e.EvalWithEnv(keyNode, env)
+	for _, pairNode := range node.Pairs {
+		key := e.EvalWithEnv(pairNode.Key, env)
 		if isError(key) {
 			return key
 		}
 
-		hashKey, ok := key.(object.Hashable)
-		if !ok {
-			return newError("chave de hash inválida: %T", key)
+		if !object.IsHashable(key) {
+			return invalidHashKeyError(key)
 		}
 
-		value := e.EvalWithEnv(valueNode, env)
+		value := e.EvalWithEnv(pairNode.Value, env)
 		if isError(value) {
 			return value
 		}
 
-		hashed := hashKey.HashKey()
-		pairs[hashed] = object.HashPair{Key: key, Value: value}
+		hash.Set(key, value)
 	}
 
-	return &object.Hash{Pairs: pairs}
+	return hash
 }
 
 func (e *Evaluator) evalHashIndexExpression(hash, index object.Object) object.Object {
 	hashObject := hash.(*object.Hash)
 
-	key, ok := index.(object.Hashable)
-	if !ok {
-		return newError("chave de hash inválida: %T", index)
+	if !object.IsHashable(index) {
+		return invalidHashKeyError(index)
 	}
 
-	pair, ok := hashObject.Pairs[key.HashKey()]
+	pair, ok := hashObject.Get(index)
 	if !ok {
 		return NULL
 	}
@@ -517,6 +1665,37 @@ func (e *Evaluator) evalHashIndexExpression(hash, index object.Object) object.Ob
 	return pair.Value
 }
 
+func invalidHashKeyError(key object.Object) *object.Error {
+	if key.Type() == object.ARRAY_OBJ {
+		return newError("chave de hash inválida: listas podem ser alteradas; use uma tupla, como tupla(lista)")
+	}
+	return newError("chave de hash inválida: %s", key.Type())
+}
+
+func isInteger(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.BIG_INTEGER_OBJ
+}
+
+func isBitwiseOperator(operator string) bool {
+	switch operator {
+	case "&", "|", "^", "<<", ">>":
+		return true
+	default:
+		return false
+	}
+}
+
+func toBigInt(obj object.Object) *big.Int {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return big.NewInt(obj.Value)
+	case *object.BigInteger:
+		return obj.Value
+	default:
+		return nil
+	}
+}
+
 func isTruthy(obj object.Object) bool {
 	switch obj {
 	case NULL:
@@ -544,35 +1723,59 @@ func newError(format string, a ...interface{}) *object.Error {
 func (e *Evaluator) evalIncludeStatement(node *ast.IncludeStatement, env *object.Environment) object.Object {
 	libraryName := node.Library.Value
 
-	err := e.libraryManager.LoadLibrary(libraryName)
+	lib, err := e.libraryManager.LoadLibrary(libraryName)
 	if err != nil {
 		return newError(err.Error())
 	}
 
-
-	if libraryName == "smath" {
-		smathLib := library.NewSMathLibrary()
-		builtins := smathLib.GetBuiltins()
-
-
-		for name, fn := range builtins {
+	// funções de bibliotecas são constantes, para não serem trocadas por engano
+	for name, fn := range lib.GetBuiltins() {
+		if e.options.AllowShadowing {
 			env.Set(name, fn)
+		} else {
+			env.SetConstant(name, fn)
 		}
 	}
 
 	return NULL
 }
 
-func (e *Evaluator) needsMathLibrary(operator string) bool {
+// checkDeclaration recusa declarar name quando isso redeclararia uma
+// constante do mesmo escopo ou, no escopo global, esconderia uma função
+// built-in. Escopos internos podem reutilizar esses nomes.
+func (e *Evaluator) checkDeclaration(env *object.Environment, name string) *object.Error {
+	if env.HasOwn(name) && env.IsConstant(name) {
+		return newError("constante %s não pode ser redeclarada", name)
+	}
+	if _, ok := builtins[name]; ok && env.IsGlobal() && !e.options.AllowShadowing {
+		return newError("%s é uma função built-in e não pode ser redeclarada no escopo global", name)
+	}
+	return nil
+}
 
+// checkVariableDeclaration aplica a regra de redeclaração de variáveis: um
+// nome só pode ser declarado uma vez por escopo, e um bloco (se, para, caso)
+// não pode declarar de novo um nome visível da mesma função — para alterar
+// o valor, use uma atribuição. Funções podem reutilizar nomes globais.
+func (e *Evaluator) checkVariableDeclaration(env *object.Environment, name string) *object.Error {
+	if err := e.checkDeclaration(env, name); err != nil {
+		return err
+	}
+	if env.HasOwn(name) {
+		return newError("variável %s já declarada neste escopo", name)
+	}
+	if env.DeclaredInFunction(name) {
+		return newError("variável %s já declarada em um escopo externo; para alterar o valor use %s = ...", name, name)
+	}
+	return nil
+}
 
-	mathOperators := []string{"/", "%"}
-	for _, op := range mathOperators {
-		if operator == op {
-			return true
-		}
+func (e *Evaluator) declare(env *object.Environment, name string, val object.Object) *object.Error {
+	if err := e.checkDeclaration(env, name); err != nil {
+		return err
 	}
-	return false
+	env.Set(name, val)
+	return nil
 }
 
 func isError(obj object.Object) bool {
@@ -581,3 +1784,356 @@ func isError(obj object.Object) bool {
 	}
 	return false
 }
+
+func (e *Evaluator) evalRecordStatement(node *ast.RecordStatement, env *object.Environment) object.Object {
+	recordType := &object.RecordType{Name: node.Name.Value, Env: env}
+
+	for _, field := range node.Fields {
+		if _, ok := recordType.FieldIndex(field.Name.Value); ok {
+			return newError("campo repetido em %s: %s", recordType.Name, field.Name.Value)
+		}
+		recordType.Fields = append(recordType.Fields, object.RecordField{
+			Name:    field.Name.Value,
+			Type:    field.Type,
+			Default: field.Default,
+		})
+	}
+
+	if err := e.declare(env, recordType.Name, recordType); err != nil {
+		return err
+	}
+	return recordType
+}
+
+func (e *Evaluator) evalEnumStatement(node *ast.EnumStatement, env *object.Environment) object.Object {
+	enum := &object.Enum{Name: node.Name.Value}
+	for i, member := range node.Members {
+		enum.Members = append(enum.Members, &object.EnumMember{Enum: enum, Name: member.Value, Ordinal: i})
+	}
+
+	if err := e.declare(env, enum.Name, enum); err != nil {
+		return err
+	}
+	return enum
+}
+
+func (e *Evaluator) evalClassStatement(node *ast.ClassStatement, env *object.Environment) object.Object {
+	class := &object.Class{
+		Name:    node.Name.Value,
+		Methods: map[string]*object.Function{},
+		Env:     env,
+	}
+
+	if node.Superclass != nil {
+		val, ok := env.Get(node.Superclass.Value)
+		if !ok {
+			return newError("classe base não encontrada: %s", node.Superclass.Value)
+		}
+		superclass, ok := val.(*object.Class)
+		if !ok {
+			return newError("%s não é uma classe", node.Superclass.Value)
+		}
+		class.Superclass = superclass
+		class.Fields = append(class.Fields, superclass.Fields...)
+	}
+
+	inherited := len(class.Fields)
+	for _, field := range node.Fields {
+		recordField := object.RecordField{
+			Name:    field.Name.Value,
+			Type:    field.Type,
+			Default: field.Default,
+			Env:     env,
+		}
+		idx, ok := class.FieldIndex(field.Name.Value)
+		switch {
+		case !ok:
+			class.Fields = append(class.Fields, recordField)
+		case idx < inherited:
+			// uma subclasse pode redefinir o padrão de um campo herdado
+			class.Fields[idx] = recordField
+		default:
+			return newError("campo repetido em %s: %s", class.Name, field.Name.Value)
+		}
+	}
+
+	for _, method := range node.Methods {
+		if _, ok := class.Methods[method.Name.Value]; ok {
+			return newError("método repetido em %s: %s", class.Name, method.Name.Value)
+		}
+		class.Methods[method.Name.Value] = &object.Function{
+			Name:       method.Name.Value,
+			Parameters: method.Parameters,
+			ReturnType: method.ReturnType,
+			Body:       method.Body,
+			Env:        env,
+		}
+	}
+
+	if err := e.declare(env, class.Name, class); err != nil {
+		return err
+	}
+	return class
+}
+
+// instantiateClass avalia os valores padrão dos campos e chama o método
+// construtor, se houver. Sem construtor, os argumentos preenchem os campos
+// como em um registro.
+func (e *Evaluator) instantiateClass(class *object.Class, args []object.Object, named []namedArgument) object.Object {
+	instance := &object.Instance{Class: class, Values: make([]object.Object, len(class.Fields))}
+
+	for i, field := range class.Fields {
+		instance.Values[i] = NULL
+		if field.Default == nil {
+			continue
+		}
+		value := e.EvalWithEnv(field.Default, field.Env)
+		if isError(value) {
+			return value
+		}
+		if err := checkFieldType(class.Name, field, value); err != nil {
+			return err
+		}
+		instance.Values[i] = value
+	}
+
+	constructor, owner := class.FindMethod("construtor")
+	if constructor == nil {
+		return e.fillInstanceFields(instance, args, named)
+	}
+
+	bound := &object.BoundMethod{Receiver: instance, Method: constructor, Owner: owner, Name: "construtor"}
+	if result := e.applyFunction(bound, args, named, nil); isError(result) {
+		return result
+	}
+
+	return instance
+}
+
+func (e *Evaluator) fillInstanceFields(instance *object.Instance, args []object.Object, named []namedArgument) object.Object {
+	class := instance.Class
+	if len(args) > len(class.Fields) {
+		return newError("%s tem %d campos, recebidos %d argumentos", class.Name, len(class.Fields), len(args))
+	}
+
+	for i, arg := range args {
+		if err := checkFieldType(class.Name, class.Fields[i], arg); err != nil {
+			return err
+		}
+		instance.Values[i] = arg
+	}
+
+	for _, arg := range named {
+		idx, ok := class.FieldIndex(arg.name)
+		if !ok {
+			return newError("classe %s não tem o campo %s", class.Name, arg.name)
+		}
+		if idx < len(args) {
+			return newError("campo %s de %s informado mais de uma vez", arg.name, class.Name)
+		}
+		if err := checkFieldType(class.Name, class.Fields[idx], arg.value); err != nil {
+			return err
+		}
+		instance.Values[idx] = arg.value
+	}
+
+	return instance
+}
+
+// instantiateRecord preenche os campos na ordem: argumentos posicionais,
+// depois nomeados e por fim os valores padrão. Campos sem padrão são
+// obrigatórios.
+func (e *Evaluator) instantiateRecord(recordType *object.RecordType, args []object.Object, named []namedArgument) object.Object {
+	if len(args) > len(recordType.Fields) {
+		return newError("%s tem %d campos, recebidos %d argumentos", recordType.Name, len(recordType.Fields), len(args))
+	}
+
+	values := make([]object.Object, len(recordType.Fields))
+	copy(values, args)
+
+	for _, arg := range named {
+		idx, ok := recordType.FieldIndex(arg.name)
+		if !ok {
+			return newError("registro %s não tem o campo %s", recordType.Name, arg.name)
+		}
+		if values[idx] != nil {
+			return newError("campo %s de %s informado mais de uma vez", arg.name, recordType.Name)
+		}
+		values[idx] = arg.value
+	}
+
+	for i, field := range recordType.Fields {
+		if values[i] == nil {
+			if field.Default == nil {
+				return newError("campo obrigatório ausente em %s: %s", recordType.Name, field.Name)
+			}
+			value := e.EvalWithEnv(field.Default, recordType.Env)
+			if isError(value) {
+				return value
+			}
+			values[i] = value
+		}
+
+		if err := checkFieldType(recordType.Name, field, values[i]); err != nil {
+			return err
+		}
+	}
+
+	return &object.Record{RecordType: recordType, Values: values}
+}
+
+func (e *Evaluator) evalMemberExpression(obj object.Object, name string) object.Object {
+	switch obj := obj.(type) {
+	case *object.Record:
+		idx, ok := obj.RecordType.FieldIndex(name)
+		if !ok {
+			return newError("registro %s não tem o campo %s", obj.RecordType.Name, name)
+		}
+		return obj.Values[idx]
+	case *object.Instance:
+		if idx, ok := obj.Class.FieldIndex(name); ok {
+			return obj.Values[idx]
+		}
+		if method, owner := obj.Class.FindMethod(name); method != nil {
+			return &object.BoundMethod{Receiver: obj, Method: method, Owner: owner, Name: name}
+		}
+		return newError("objeto %s não tem o membro %s", obj.Class.Name, name)
+	case *object.Enum:
+		if member, ok := obj.Member(name); ok {
+			return member
+		}
+		return newError("enumeracao %s não tem o membro %s", obj.Name, name)
+	case *object.EnumMember:
+		switch name {
+		case "nome":
+			return &object.String{Value: obj.Name}
+		case "ordem":
+			return &object.Integer{Value: int64(obj.Ordinal)}
+		default:
+			return newError("membro de enumeracao só tem nome e ordem, recebido %s", name)
+		}
+	case *object.Super:
+		if method, owner := obj.Class.FindMethod(name); method != nil {
+			return &object.BoundMethod{Receiver: obj.Receiver, Method: method, Owner: owner, Name: name}
+		}
+		return newError("%s e suas superclasses não têm o método %s", obj.Class.Name, name)
+	default:
+		return newError("acesso a membro não suportado: %s.%s", obj.Type(), name)
+	}
+}
+
+func (e *Evaluator) evalMemberAssignment(obj object.Object, name string, val object.Object) object.Object {
+	switch obj := obj.(type) {
+	case *object.Record:
+		idx, ok := obj.RecordType.FieldIndex(name)
+		if !ok {
+			return newError("registro %s não tem o campo %s", obj.RecordType.Name, name)
+		}
+		if err := checkFieldType(obj.RecordType.Name, obj.RecordType.Fields[idx], val); err != nil {
+			return err
+		}
+		obj.Values[idx] = val
+		return val
+	case *object.Instance:
+		idx, ok := obj.Class.FieldIndex(name)
+		if !ok {
+			return newError("classe %s não tem o campo %s", obj.Class.Name, name)
+		}
+		if err := checkFieldType(obj.Class.Name, obj.Class.Fields[idx], val); err != nil {
+			return err
+		}
+		obj.Values[idx] = val
+		return val
+	default:
+		return newError("atribuição a membro não suportada: %s.%s", obj.Type(), name)
+	}
+}
+
+func checkFieldType(owner string, field object.RecordField, val object.Object) *object.Error {
+	if field.Type == "" || typeMatches(field.Type, val) {
+		return nil
+	}
+	return newError("campo %s de %s deve ser %s, recebido %s", field.Name, owner, field.Type, typeName(val))
+}
+
+// typeName é o nome de tipo da linguagem para val, o mesmo das anotações e
+// das mensagens de "sovy tipos".
+func typeName(val object.Object) string {
+	switch val := val.(type) {
+	case *object.Integer, *object.BigInteger, *object.Float, *object.Decimal:
+		return "numero"
+	case *object.String:
+		return "texto"
+	case *object.Boolean:
+		return "booleano"
+	case *object.Array:
+		return "lista"
+	case *object.Hash:
+		return "mapa"
+	case *object.Set:
+		return "conjunto"
+	case *object.Tuple:
+		return "tupla"
+	case *object.Function, *object.Builtin, *object.BoundMethod:
+		return "funcao"
+	case *object.Null:
+		return "nulo"
+	case *object.Enum:
+		return "enumeracao"
+	case *object.EnumMember:
+		return val.Enum.Name
+	case *object.Record:
+		return val.RecordType.Name
+	case *object.Instance:
+		return val.Class.Name
+	default:
+		return strings.ToLower(string(val.Type()))
+	}
+}
+
+// typeMatches verifica um valor contra um tipo declarado no código fonte.
+// Nomes que não são tipos da linguagem se referem a registros, enumerações
+// ou classes (incluindo subclasses). nulo é aceito
+// em qualquer tipo.
+func typeMatches(typeName string, val object.Object) bool {
+	if val == NULL {
+		return true
+	}
+
+	switch typeName {
+	case "numero":
+		return object.IsNumber(val)
+	case "texto":
+		return val.Type() == object.STRING_OBJ
+	case "booleano":
+		return val.Type() == object.BOOLEAN_OBJ
+	case "lista":
+		return val.Type() == object.ARRAY_OBJ
+	case "mapa":
+		return val.Type() == object.HASH_OBJ
+	case "conjunto":
+		return val.Type() == object.SET_OBJ
+	case "tupla":
+		return val.Type() == object.TUPLE_OBJ
+	case "funcao", "função":
+		switch val.(type) {
+		case *object.Function, *object.Builtin, *object.BoundMethod:
+			return true
+		}
+		return false
+	default:
+		switch val := val.(type) {
+		case *object.Record:
+			return val.RecordType.Name == typeName
+		case *object.EnumMember:
+			return val.Enum.Name == typeName
+		case *object.Instance:
+			for class := val.Class; class != nil; class = class.Superclass {
+				if class.Name == typeName {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
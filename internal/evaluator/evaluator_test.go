@@ -0,0 +1,80 @@
+package evaluator
+
+import (
+	"testing"
+
+	"sovylang/internal/lexer"
+	"sovylang/internal/object"
+	"sovylang/internal/parser"
+)
+
+func TestOrdenarKeepsOriginal(t *testing.T) {
+	env := testEval(t, `
+lista l = [3, 1, 2]
+lista ordenada = ordenar(l)
+tupla t = (3, 1, 2)
+lista da_tupla = ordenar(t)
+`)
+
+	expectValues(t, env, map[string]string{
+		"l":        "[3, 1, 2]",
+		"ordenada": "[1, 2, 3]",
+		"t":        "(3, 1, 2)",
+		"da_tupla": "[1, 2, 3]",
+	})
+}
+
+func TestSliceHugeStep(t *testing.T) {
+	env := testEval(t, `
+lista l = [1, 2, 3]
+lista frente = l[1::9223372036854775807]
+lista tras = l[1::-9223372036854775807]
+lista tudo = l[::9223372036854775807]
+lista vazia = [][::-9223372036854775807]
+`)
+
+	expectValues(t, env, map[string]string{
+		"frente": "[2]",
+		"tras":   "[2]",
+		"tudo":   "[1]",
+		"vazia":  "[]",
+	})
+}
+
+func TestTupleReturnType(t *testing.T) {
+	env := testEval(t, `
+funcao par() -> tupla
+    retorne 1, 2
+fim
+tupla p = par()
+`)
+
+	expectValues(t, env, map[string]string{"p": "(1, 2)"})
+}
+
+func expectValues(t *testing.T, env *object.Environment, expected map[string]string) {
+	t.Helper()
+	for name, want := range expected {
+		val, ok := env.Get(name)
+		if !ok {
+			t.Fatalf("%s não definido", name)
+		}
+		if val.Inspect() != want {
+			t.Errorf("%s = %s, esperado %s", name, val.Inspect(), want)
+		}
+	}
+}
+
+func testEval(t *testing.T, input string) *object.Environment {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("erros de sintaxe: %v", p.Errors())
+	}
+	env := object.NewEnvironment()
+	if result := New().EvalWithEnv(program, env); isError(result) {
+		t.Fatalf("erro de execução: %s", result.Inspect())
+	}
+	return env
+}
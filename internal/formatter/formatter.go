@@ -3,6 +3,7 @@ package formatter
 import (
 	"bytes"
 	"sovylang/internal/ast"
+	"sovylang/internal/parser"
 	"strings"
 )
 
@@ -36,12 +37,24 @@ func (f *Formatter) formatStatement(stmt ast.Statement) string {
 	switch s := stmt.(type) {
 	case *ast.VarStatement:
 		return f.formatVarStatement(s)
+	case *ast.AssignStatement:
+		return f.formatAssignStatement(s)
+	case *ast.DestructureStatement:
+		return f.formatDestructureStatement(s)
 	case *ast.ReturnStatement:
 		return f.formatReturnStatement(s)
 	case *ast.ExpressionStatement:
 		return f.formatExpressionStatement(s)
 	case *ast.ForStatement:
 		return f.formatForStatement(s)
+	case *ast.ForEachStatement:
+		return f.formatForEachStatement(s)
+	case *ast.RecordStatement:
+		return f.formatRecordStatement(s)
+	case *ast.ClassStatement:
+		return f.formatClassStatement(s)
+	case *ast.EnumStatement:
+		return f.formatEnumStatement(s)
 	case *ast.BlockStatement:
 		return f.formatBlockStatement(s)
 	default:
@@ -52,17 +65,63 @@ func (f *Formatter) formatStatement(stmt ast.Statement) string {
 func (f *Formatter) formatVarStatement(vs *ast.VarStatement) string {
 	var out bytes.Buffer
 	out.WriteString(f.indent())
-	out.WriteString(vs.Type + " " + vs.Name.Value + " = ")
+	if vs.Constant {
+		out.WriteString("constante ")
+	}
+	if vs.Type != "" {
+		out.WriteString(vs.Type + " ")
+	}
+	out.WriteString(vs.Name.Value + " = ")
 	out.WriteString(f.formatExpression(vs.Value))
 	return out.String()
 }
 
+func (f *Formatter) formatAssignStatement(as *ast.AssignStatement) string {
+	var out bytes.Buffer
+	out.WriteString(f.indent())
+	out.WriteString(f.formatExpressionOrTuple(as.Target) + " = ")
+	out.WriteString(f.formatExpressionOrTuple(as.Value))
+	return out.String()
+}
+
+func (f *Formatter) formatDestructureStatement(ds *ast.DestructureStatement) string {
+	var out bytes.Buffer
+	out.WriteString(f.indent())
+	
+	names := []string{}
+	for _, name := range ds.Names {
+		names = append(names, name.Value)
+	}
+	if ds.IsMap() {
+		out.WriteString("mapa {" + strings.Join(names, ", ") + "} = ")
+	} else {
+		out.WriteString("lista [" + strings.Join(names, ", ") + "] = ")
+	}
+	out.WriteString(f.formatExpressionOrTuple(ds.Value))
+	return out.String()
+}
+
+// formatExpressionOrTuple escreve tuplas de dois ou mais elementos sem
+// parênteses, como em "retorne a, b" e "a, b = b, a".
+func (f *Formatter) formatExpressionOrTuple(exp ast.Expression) string {
+	tuple, ok := exp.(*ast.TupleLiteral)
+	if !ok || len(tuple.Elements) < 2 {
+		return f.formatExpression(exp)
+	}
+	
+	elements := []string{}
+	for _, e := range tuple.Elements {
+		elements = append(elements, f.formatExpression(e))
+	}
+	return strings.Join(elements, ", ")
+}
+
 func (f *Formatter) formatReturnStatement(rs *ast.ReturnStatement) string {
 	var out bytes.Buffer
 	out.WriteString(f.indent())
 	out.WriteString("retorne ")
 	if rs.ReturnValue != nil {
-		out.WriteString(f.formatExpression(rs.ReturnValue))
+		out.WriteString(f.formatExpressionOrTuple(rs.ReturnValue))
 	}
 	return out.String()
 }
@@ -93,6 +152,87 @@ func (f *Formatter) formatForStatement(fs *ast.ForStatement) string {
 	return out.String()
 }
 
+func (f *Formatter) formatForEachStatement(fs *ast.ForEachStatement) string {
+	var out bytes.Buffer
+	out.WriteString(f.indent())
+	out.WriteString("para cada " + fs.Variable.Value + " em ")
+	out.WriteString(f.formatExpression(fs.Iterable))
+	out.WriteString("\n")
+	
+	f.indentLevel++
+	out.WriteString(f.formatBlockStatement(fs.Body))
+	f.indentLevel--
+	
+	out.WriteString("\n" + f.indent() + "fim")
+	return out.String()
+}
+
+func (f *Formatter) formatRecordStatement(rs *ast.RecordStatement) string {
+	var out bytes.Buffer
+	out.WriteString(f.indent())
+	out.WriteString("registro " + rs.Name.Value)
+	
+	f.indentLevel++
+	for _, field := range rs.Fields {
+		out.WriteString("\n" + f.formatRecordField(field))
+	}
+	f.indentLevel--
+	
+	out.WriteString("\n" + f.indent() + "fim")
+	return out.String()
+}
+
+func (f *Formatter) formatRecordField(field *ast.RecordField) string {
+	var out bytes.Buffer
+	out.WriteString(f.indent())
+	if field.Type != "" {
+		out.WriteString(field.Type + " ")
+	}
+	out.WriteString(field.Name.Value)
+	if field.Default != nil {
+		out.WriteString(" = " + f.formatExpression(field.Default))
+	}
+	return out.String()
+}
+
+func (f *Formatter) formatEnumStatement(es *ast.EnumStatement) string {
+	var out bytes.Buffer
+	out.WriteString(f.indent())
+	out.WriteString("enumeracao " + es.Name.Value + "\n")
+	
+	members := []string{}
+	for _, m := range es.Members {
+		members = append(members, m.Value)
+	}
+	f.indentLevel++
+	out.WriteString(f.indent() + strings.Join(members, ", "))
+	f.indentLevel--
+	
+	out.WriteString("\n" + f.indent() + "fim")
+	return out.String()
+}
+
+func (f *Formatter) formatClassStatement(cs *ast.ClassStatement) string {
+	var out bytes.Buffer
+	out.WriteString(f.indent())
+	out.WriteString("classe " + cs.Name.Value)
+	if cs.Superclass != nil {
+		out.WriteString(" herda " + cs.Superclass.Value)
+	}
+	
+	f.indentLevel++
+	for _, field := range cs.Fields {
+		out.WriteString("\n" + f.formatRecordField(field))
+	}
+	for _, method := range cs.Methods {
+		out.WriteString("\n\n" + f.indent() + f.formatFunctionLiteral(method))
+	}
+	f.indentLevel--
+	
+	out.WriteString("\n" + f.indent() + "fim")
+	return out.String()
+}
+
 func (f *Formatter) formatBlockStatement(bs *ast.BlockStatement) string {
 	var out bytes.Buffer
 	
@@ -122,29 +262,77 @@ func (f *Formatter) formatExpression(exp ast.Expression) string {
 		return f.formatPrefixExpression(e)
 	case *ast.InfixExpression:
 		return f.formatInfixExpression(e)
+	case *ast.LogicalExpression:
+		return f.formatLogicalExpression(e)
 	case *ast.IfExpression:
 		return f.formatIfExpression(e)
+	case *ast.SwitchExpression:
+		return f.formatSwitchExpression(e)
+	case *ast.RangePattern:
+		return f.formatExpression(e.Low) + " até " + f.formatExpression(e.High)
 	case *ast.FunctionLiteral:
 		return f.formatFunctionLiteral(e)
 	case *ast.CallExpression:
 		return f.formatCallExpression(e)
 	case *ast.ArrayLiteral:
 		return f.formatArrayLiteral(e)
+	case *ast.TupleLiteral:
+		return f.formatTupleLiteral(e)
+	case *ast.SetLiteral:
+		return f.formatSetLiteral(e)
 	case *ast.HashLiteral:
 		return f.formatHashLiteral(e)
 	case *ast.IndexExpression:
 		return f.formatIndexExpression(e)
+	case *ast.MemberExpression:
+		return f.formatExpression(e.Object) + "." + e.Property.Value
+	case *ast.NamedArgument:
+		return e.Name.Value + ": " + f.formatExpression(e.Value)
+	case *ast.SliceExpression:
+		return f.formatSliceExpression(e)
 	default:
 		return e.String()
 	}
 }
 
 func (f *Formatter) formatPrefixExpression(pe *ast.PrefixExpression) string {
+	if _, ok := binaryPrecedence(pe.Right); ok {
+		return pe.Operator + "(" + f.formatExpression(pe.Right) + ")"
+	}
 	return pe.Operator + f.formatExpression(pe.Right)
 }
 
 func (f *Formatter) formatInfixExpression(ie *ast.InfixExpression) string {
-	return f.formatExpression(ie.Left) + " " + ie.Operator + " " + f.formatExpression(ie.Right)
+	precedence := parser.Precedence(ie.Token.Type)
+	return f.formatOperand(ie.Left, precedence, false) + " " + ie.Operator + " " + f.formatOperand(ie.Right, precedence, true)
+}
+
+func (f *Formatter) formatLogicalExpression(le *ast.LogicalExpression) string {
+	precedence := parser.Precedence(le.Token.Type)
+	return f.formatOperand(le.Left, precedence, false) + " " + le.Operator + " " + f.formatOperand(le.Right, precedence, true)
+}
+
+// A árvore não guarda os parênteses do código; formatOperand os repõe a
+// partir das precedências do parser. exp fica entre parênteses quando tem
+// precedência menor que a do operador pai, ou igual se estiver à direita
+// (os operadores associam à esquerda).
+func (f *Formatter) formatOperand(exp ast.Expression, parent int, right bool) string {
+	text := f.formatExpression(exp)
+	precedence, ok := binaryPrecedence(exp)
+	if ok && (precedence < parent || right && precedence == parent) {
+		return "(" + text + ")"
+	}
+	return text
+}
+
+func binaryPrecedence(exp ast.Expression) (int, bool) {
+	switch e := exp.(type) {
+	case *ast.InfixExpression:
+		return parser.Precedence(e.Token.Type), true
+	case *ast.LogicalExpression:
+		return parser.Precedence(e.Token.Type), true
+	}
+	return 0, false
 }
 
 func (f *Formatter) formatIfExpression(ie *ast.IfExpression) string {
@@ -169,6 +357,39 @@ func (f *Formatter) formatIfExpression(ie *ast.IfExpression) string {
 	return out.String()
 }
 
+func (f *Formatter) formatSwitchExpression(se *ast.SwitchExpression) string {
+	var out bytes.Buffer
+	out.WriteString("escolha " + f.formatExpression(se.Subject))
+	
+	f.indentLevel++
+	for _, c := range se.Cases {
+		patterns := []string{}
+		for _, p := range c.Patterns {
+			patterns = append(patterns, f.formatExpression(p))
+		}
+		out.WriteString("\n" + f.indent() + "caso " + strings.Join(patterns, ", "))
+		if c.Guard != nil {
+			out.WriteString(" se " + f.formatExpression(c.Guard))
+		}
+		out.WriteString("\n")
+		
+		f.indentLevel++
+		out.WriteString(f.formatBlockStatement(c.Body))
+		f.indentLevel--
+	}
+	if se.Default != nil {
+		out.WriteString("\n" + f.indent() + "padrão\n")
+		
+		f.indentLevel++
+		out.WriteString(f.formatBlockStatement(se.Default))
+		f.indentLevel--
+	}
+	f.indentLevel--
+	
+	out.WriteString("\n" + f.indent() + "fim")
+	return out.String()
+}
+
 func (f *Formatter) formatFunctionLiteral(fl *ast.FunctionLiteral) string {
 	var out bytes.Buffer
 	
@@ -180,10 +401,27 @@ func (f *Formatter) formatFunctionLiteral(fl *ast.FunctionLiteral) string {
 	
 	params := []string{}
 	for _, p := range fl.Parameters {
-		params = append(params, p.String())
+		param := p.Name.Value
+		if p.Variadic {
+			param = "..." + param
+		}
+		if p.Type != "" {
+			param = p.Type + " " + param
+		}
+		if p.Default != nil {
+			param += " = " + f.formatExpression(p.Default)
+		}
+		params = append(params, param)
 	}
 	out.WriteString(strings.Join(params, ", "))
 	out.WriteString(")")
+	if fl.ReturnType != "" {
+		out.WriteString(" -> " + fl.ReturnType)
+	}
+	if fl.Concise {
+		out.WriteString(" = " + f.formatExpression(fl.ConciseValue()))
+		return out.String()
+	}
 	out.WriteString("\n")
 	
 	f.indentLevel++
@@ -225,12 +463,45 @@ func (f *Formatter) formatArrayLiteral(al *ast.ArrayLiteral) string {
 	return out.String()
 }
 
+func (f *Formatter) formatTupleLiteral(tl *ast.TupleLiteral) string {
+	var out bytes.Buffer
+	
+	elements := []string{}
+	for _, e := range tl.Elements {
+		elements = append(elements, f.formatExpression(e))
+	}
+	
+	out.WriteString("(")
+	out.WriteString(strings.Join(elements, ", "))
+	if len(elements) == 1 {
+		out.WriteString(",")
+	}
+	out.WriteString(")")
+	
+	return out.String()
+}
+
+func (f *Formatter) formatSetLiteral(sl *ast.SetLiteral) string {
+	var out bytes.Buffer
+	
+	elements := []string{}
+	for _, e := range sl.Elements {
+		elements = append(elements, f.formatExpression(e))
+	}
+	
+	out.WriteString("conjunto{")
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString("}")
+	
+	return out.String()
+}
+
 func (f *Formatter) formatHashLiteral(hl *ast.HashLiteral) string {
 	var out bytes.Buffer
 	
 	pairs := []string{}
-	for key, value := range hl.Pairs {
-		pairs = append(pairs, f.formatExpression(key)+": "+f.formatExpression(value))
+	for _, pair := range hl.Pairs {
+		pairs = append(pairs, f.formatExpression(pair.Key)+": "+f.formatExpression(pair.Value))
 	}
 	
 	out.WriteString("{")
@@ -241,7 +512,34 @@ func (f *Formatter) formatHashLiteral(hl *ast.HashLiteral) string {
 }
 
 func (f *Formatter) formatIndexExpression(ie *ast.IndexExpression) string {
-	return f.formatExpression(ie.Left) + "[" + f.formatExpression(ie.Index) + "]"
+	open := "["
+	if ie.Optional {
+		open = "?["
+	}
+	return f.formatExpression(ie.Left) + open + f.formatExpression(ie.Index) + "]"
+}
+
+func (f *Formatter) formatSliceExpression(se *ast.SliceExpression) string {
+	var out bytes.Buffer
+
+	out.WriteString(f.formatExpression(se.Left))
+	if se.Optional {
+		out.WriteString("?")
+	}
+	out.WriteString("[")
+	if se.Start != nil {
+		out.WriteString(f.formatExpression(se.Start))
+	}
+	out.WriteString(":")
+	if se.End != nil {
+		out.WriteString(f.formatExpression(se.End))
+	}
+	if se.Step != nil {
+		out.WriteString(":" + f.formatExpression(se.Step))
+	}
+	out.WriteString("]")
+
+	return out.String()
 }
 
 func (f *Formatter) indent() string {
@@ -0,0 +1,38 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+
+	"sovylang/internal/lexer"
+	"sovylang/internal/parser"
+)
+
+func TestFormatKeepsGrouping(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"booleano x = (verdadeiro ou falso) e falso", "booleano x = (verdadeiro ou falso) e falso"},
+		{"booleano x = verdadeiro ou falso e falso", "booleano x = verdadeiro ou falso e falso"},
+		{"booleano x = (a ?? b) ou c", "booleano x = (a ?? b) ou c"},
+		{"booleano x = a ?? (b ou c)", "booleano x = a ?? b ou c"},
+		{"numero y = (1 + 2) * 3", "numero y = (1 + 2) * 3"},
+		{"numero y = 1 - (2 - 3)", "numero y = 1 - (2 - 3)"},
+		{"numero y = (1 - 2) - 3", "numero y = 1 - 2 - 3"},
+		{"booleano z = (a ou b) == c", "booleano z = (a ou b) == c"},
+		{"booleano z = não (a e b)", "booleano z = não(a e b)"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("%q: erros de sintaxe: %v", tt.input, p.Errors())
+		}
+		got := strings.TrimSpace(Format(program))
+		if got != tt.expected {
+			t.Errorf("%q: formatado como %q, esperado %q", tt.input, got, tt.expected)
+		}
+	}
+}
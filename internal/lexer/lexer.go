@@ -11,6 +11,7 @@ type Lexer struct {
 	ch           byte
 	line         int
 	column       int
+	bracketDepth int
 }
 
 func New(input string) *Lexer {
@@ -58,7 +59,12 @@ func (l *Lexer) NextToken() token.Token {
 	case '+':
 		tok = newToken(token.PLUS, l.ch, l.line, l.column)
 	case '-':
-		tok = newToken(token.MINUS, l.ch, l.line, l.column)
+		if l.peekChar() == '>' {
+			l.readChar()
+			tok = token.Token{Type: token.ARROW, Literal: "->", Line: l.line, Column: l.column - 1}
+		} else {
+			tok = newToken(token.MINUS, l.ch, l.line, l.column)
+		}
 	case '!':
 		if l.peekChar() == '=' {
 			ch := l.ch
@@ -74,8 +80,21 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.ASTERISK, l.ch, l.line, l.column)
 	case '%':
 		tok = newToken(token.PERCENT, l.ch, l.line, l.column)
+	case '&':
+		tok = newToken(token.AMPERSAND, l.ch, l.line, l.column)
+	case '|':
+		tok = newToken(token.PIPE, l.ch, l.line, l.column)
+	case '^':
+		tok = newToken(token.CARET, l.ch, l.line, l.column)
+	case '~':
+		tok = newToken(token.TILDE, l.ch, l.line, l.column)
 	case '<':
-		if l.peekChar() == '=' {
+		if l.peekChar() == '<' {
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			tok = token.Token{Type: token.SHIFT_LEFT, Literal: literal, Line: l.line, Column: l.column - 1}
+		} else if l.peekChar() == '=' {
 			ch := l.ch
 			l.readChar()
 			literal := string(ch) + string(l.ch)
@@ -84,7 +103,12 @@ func (l *Lexer) NextToken() token.Token {
 			tok = newToken(token.LT, l.ch, l.line, l.column)
 		}
 	case '>':
-		if l.peekChar() == '=' {
+		if l.peekChar() == '>' {
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			tok = token.Token{Type: token.SHIFT_RIGHT, Literal: literal, Line: l.line, Column: l.column - 1}
+		} else if l.peekChar() == '=' {
 			ch := l.ch
 			l.readChar()
 			literal := string(ch) + string(l.ch)
@@ -92,10 +116,24 @@ func (l *Lexer) NextToken() token.Token {
 		} else {
 			tok = newToken(token.GT, l.ch, l.line, l.column)
 		}
+	case '?':
+		if l.peekChar() == '?' || l.peekChar() == '[' {
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			tokenType := token.TokenType(token.COALESCE)
+			if l.ch == '[' {
+				tokenType = token.OPTIONAL_INDEX
+				l.bracketDepth++
+			}
+			tok = token.Token{Type: tokenType, Literal: literal, Line: l.line, Column: l.column - 1}
+		} else {
+			tok = newToken(token.ILLEGAL, l.ch, l.line, l.column)
+		}
 	case ';':
 		tok = newToken(token.SEMICOLON, l.ch, l.line, l.column)
 	case ':':
-		if l.peekChar() == ':' {
+		if l.peekChar() == ':' && l.bracketDepth == 0 {
 
 			l.readChar()
 			l.readChar()
@@ -106,6 +144,14 @@ func (l *Lexer) NextToken() token.Token {
 		}
 	case ',':
 		tok = newToken(token.COMMA, l.ch, l.line, l.column)
+	case '.':
+		if l.peekChar() == '.' && l.readPosition+1 < len(l.input) && l.input[l.readPosition+1] == '.' {
+			l.readChar()
+			l.readChar()
+			tok = token.Token{Type: token.ELLIPSIS, Literal: "...", Line: l.line, Column: l.column - 2}
+		} else {
+			tok = newToken(token.DOT, l.ch, l.line, l.column)
+		}
 	case '{':
 		tok = newToken(token.LBRACE, l.ch, l.line, l.column)
 	case '}':
@@ -115,14 +161,18 @@ func (l *Lexer) NextToken() token.Token {
 	case ')':
 		tok = newToken(token.RPAREN, l.ch, l.line, l.column)
 	case '[':
+		l.bracketDepth++
 		tok = newToken(token.LBRACKET, l.ch, l.line, l.column)
 	case ']':
+		if l.bracketDepth > 0 {
+			l.bracketDepth--
+		}
 		tok = newToken(token.RBRACKET, l.ch, l.line, l.column)
 	case '"':
 		tok.Type = token.STRING
-		tok.Literal = l.readString()
 		tok.Line = l.line
 		tok.Column = l.column
+		tok.Literal = l.readString()
 	case '\n':
 		tok = newToken(token.NEWLINE, l.ch, l.line, l.column)
 	case 0:
@@ -132,15 +182,15 @@ func (l *Lexer) NextToken() token.Token {
 		tok.Column = l.column
 	default:
 		if isLetter(l.ch) {
-			tok.Literal = l.readIdentifier()
-			tok.Type = token.LookupIdent(tok.Literal)
 			tok.Line = l.line
 			tok.Column = l.column
+			tok.Literal = l.readIdentifier()
+			tok.Type = token.LookupIdent(tok.Literal)
 			return tok
 		} else if isDigit(l.ch) {
-			tok.Type, tok.Literal = l.readNumber()
 			tok.Line = l.line
 			tok.Column = l.column
+			tok.Type, tok.Literal = l.readNumber()
 			return tok
 		} else {
 			tok = newToken(token.ILLEGAL, l.ch, l.line, l.column)
@@ -1,14 +1,52 @@
 package library
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+
+	"sovylang/internal/object"
 )
 
+type Library interface {
+	Name() string
+	Version() string
+	Description() string
+	Functions() map[string]string
+	GetBuiltins() map[string]*object.Builtin
+}
+
+var nativeLibraries = map[string]Library{
+	"smath": NewSMathLibrary(),
+}
+
+func Lookup(name string) (Library, bool) {
+	lib, ok := nativeLibraries[name]
+	return lib, ok
+}
+
+func AvailableLibraries() []Library {
+	var libraries []Library
+	for _, lib := range nativeLibraries {
+		libraries = append(libraries, lib)
+	}
+	sort.Slice(libraries, func(i, j int) bool {
+		return libraries[i].Name() < libraries[j].Name()
+	})
+	return libraries
+}
+
+type manifest struct {
+	Name        string            `json:"name"`
+	Version     string            `json:"version"`
+	Description string            `json:"description"`
+	Functions   map[string]string `json:"functions"`
+}
+
 type LibraryManager struct {
-	installedLibraries map[string]bool
-	libraryPath        string
+	libraryPath string
 }
 
 func NewLibraryManager() *LibraryManager {
@@ -16,8 +54,7 @@ func NewLibraryManager() *LibraryManager {
 	libPath := filepath.Join(homeDir, ".sovy", "libraries")
 
 	return &LibraryManager{
-		installedLibraries: make(map[string]bool),
-		libraryPath:        libPath,
+		libraryPath: libPath,
 	}
 }
 
@@ -29,66 +66,47 @@ func (lm *LibraryManager) IsLibraryInstalled(name string) bool {
 }
 
 func (lm *LibraryManager) InstallLibrary(name string) error {
-	switch name {
-	case "smath":
-		return lm.installSMathLibrary()
-	default:
+	lib, ok := Lookup(name)
+	if !ok {
 		return fmt.Errorf("biblioteca '%s' não encontrada", name)
 	}
-}
-
-func (lm *LibraryManager) installSMathLibrary() error {
 
 	err := os.MkdirAll(lm.libraryPath, 0755)
 	if err != nil {
 		return fmt.Errorf("erro ao criar diretório de bibliotecas: %v", err)
 	}
 
-
-	smathContent := `{
-	"name": "smath",
-	"version": "1.0.0",
-	"description": "Biblioteca de matemática avançada para Solara",
-	"functions": {
-		"potencia": "Calcula potência (base, expoente)",
-		"raiz": "Calcula raiz quadrada",
-		"sin": "Calcula seno",
-		"cos": "Calcula cosseno",
-		"tan": "Calcula tangente",
-		"abs": "Valor absoluto",
-		"max": "Valor máximo entre dois números",
-		"min": "Valor mínimo entre dois números",
-		"pi": "Constante PI (3.14159...)"
+	content, err := json.MarshalIndent(manifest{
+		Name:        lib.Name(),
+		Version:     lib.Version(),
+		Description: lib.Description(),
+		Functions:   lib.Functions(),
+	}, "", "\t")
+	if err != nil {
+		return fmt.Errorf("erro ao gerar manifesto da biblioteca %s: %v", name, err)
 	}
-}`
 
-	libFile := filepath.Join(lm.libraryPath, "smath.slib")
-	err = os.WriteFile(libFile, []byte(smathContent), 0644)
+	libFile := filepath.Join(lm.libraryPath, name+".slib")
+	err = os.WriteFile(libFile, content, 0644)
 	if err != nil {
-		return fmt.Errorf("erro ao instalar biblioteca smath: %v", err)
+		return fmt.Errorf("erro ao instalar biblioteca %s: %v", name, err)
 	}
 
-	fmt.Println("Biblioteca 'smath' instalada com sucesso!")
 	fmt.Printf("Local: %s\n", libFile)
 	return nil
 }
 
-func (lm *LibraryManager) LoadLibrary(name string) error {
-	if !lm.IsLibraryInstalled(name) {
-		return fmt.Errorf("biblioteca '%s' não está instalada. Use: sovy install %s", name, name)
+func (lm *LibraryManager) LoadLibrary(name string) (Library, error) {
+	lib, ok := Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("biblioteca '%s' não encontrada", name)
 	}
 
-	lm.installedLibraries[name] = true
-	return nil
-}
-
-func (lm *LibraryManager) IsLibraryLoaded(name string) bool {
-	return lm.installedLibraries[name]
-}
-
-func (lm *LibraryManager) RequiresMath() bool {
+	if !lm.IsLibraryInstalled(name) {
+		return nil, fmt.Errorf("biblioteca '%s' não está instalada. Use: sovy install %s", name, name)
+	}
 
-	return !lm.IsLibraryLoaded("smath")
+	return lib, nil
 }
 
 func (lm *LibraryManager) ListInstalledLibraries() []string {
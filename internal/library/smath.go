@@ -2,6 +2,7 @@ package library
 
 import (
 	"math"
+	"math/big"
 	"sovylang/internal/object"
 )
 
@@ -12,99 +13,118 @@ func NewSMathLibrary() *SMathLibrary {
 	return &SMathLibrary{}
 }
 
+func (s *SMathLibrary) Name() string        { return "smath" }
+func (s *SMathLibrary) Version() string     { return "1.0.0" }
+func (s *SMathLibrary) Description() string { return "Biblioteca de matemática avançada para Solara" }
+
+func (s *SMathLibrary) Functions() map[string]string {
+	return map[string]string{
+		"potencia": "Calcula potência (base, expoente)",
+		"raiz":     "Calcula raiz quadrada",
+		"sin":      "Calcula seno",
+		"cos":      "Calcula cosseno",
+		"tan":      "Calcula tangente",
+		"abs":      "Valor absoluto",
+		"max":      "Valor máximo entre dois números",
+		"min":      "Valor mínimo entre dois números",
+		"pi":       "Constante PI (3.14159...)",
+	}
+}
+
 func (s *SMathLibrary) GetBuiltins() map[string]*object.Builtin {
 	return map[string]*object.Builtin{
 		"potencia": {
-			Fn: func(args ...object.Object) object.Object {
+			Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
 				if len(args) != 2 {
 					return &object.Error{Message: "potencia() requer 2 argumentos (base, expoente)"}
 				}
 
-				base, ok := args[0].(*object.Float)
-				if !ok {
-					if intBase, ok := args[0].(*object.Integer); ok {
-						base = &object.Float{Value: float64(intBase.Value)}
-					} else {
-						return &object.Error{Message: "primeiro argumento deve ser um número"}
+				if base, ok := toBigInt(args[0]); ok {
+					if exponent, ok := toBigInt(args[1]); ok && exponent.Sign() >= 0 {
+						return object.NewInteger(new(big.Int).Exp(base, exponent, nil))
 					}
 				}
 
-				exponent, ok := args[1].(*object.Float)
+				base, ok := toFloat(args[0])
 				if !ok {
-					if intExp, ok := args[1].(*object.Integer); ok {
-						exponent = &object.Float{Value: float64(intExp.Value)}
-					} else {
-						return &object.Error{Message: "segundo argumento deve ser um número"}
-					}
+					return &object.Error{Message: "primeiro argumento deve ser um número"}
+				}
+
+				exponent, ok := toFloat(args[1])
+				if !ok {
+					return &object.Error{Message: "segundo argumento deve ser um número"}
 				}
 
-				result := math.Pow(base.Value, exponent.Value)
+				result := math.Pow(base, exponent)
 				return &object.Float{Value: result}
 			},
 		},
 		"raiz": {
-			Fn: func(args ...object.Object) object.Object {
+			Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
 				if len(args) != 1 {
 					return &object.Error{Message: "raiz() requer 1 argumento"}
 				}
 
-				num, ok := args[0].(*object.Float)
+				num, ok := toFloat(args[0])
 				if !ok {
-					if intNum, ok := args[0].(*object.Integer); ok {
-						num = &object.Float{Value: float64(intNum.Value)}
-					} else {
-						return &object.Error{Message: "argumento deve ser um número"}
-					}
+					return &object.Error{Message: "argumento deve ser um número"}
 				}
 
-				if num.Value < 0 {
+				if num < 0 {
 					return &object.Error{Message: "não é possível calcular raiz de número negativo"}
 				}
 
-				result := math.Sqrt(num.Value)
+				result := math.Sqrt(num)
 				return &object.Float{Value: result}
 			},
 		},
 		"sin": {
-			Fn: func(args ...object.Object) object.Object {
+			Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
 				if len(args) != 1 {
 					return &object.Error{Message: "sin() requer 1 argumento"}
 				}
 
-				num, ok := args[0].(*object.Float)
+				num, ok := toFloat(args[0])
 				if !ok {
-					if intNum, ok := args[0].(*object.Integer); ok {
-						num = &object.Float{Value: float64(intNum.Value)}
-					} else {
-						return &object.Error{Message: "argumento deve ser um número"}
-					}
+					return &object.Error{Message: "argumento deve ser um número"}
 				}
 
-				result := math.Sin(num.Value)
+				result := math.Sin(num)
 				return &object.Float{Value: result}
 			},
 		},
 		"cos": {
-			Fn: func(args ...object.Object) object.Object {
+			Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
 				if len(args) != 1 {
 					return &object.Error{Message: "cos() requer 1 argumento"}
 				}
 
-				num, ok := args[0].(*object.Float)
+				num, ok := toFloat(args[0])
 				if !ok {
-					if intNum, ok := args[0].(*object.Integer); ok {
-						num = &object.Float{Value: float64(intNum.Value)}
-					} else {
-						return &object.Error{Message: "argumento deve ser um número"}
-					}
+					return &object.Error{Message: "argumento deve ser um número"}
 				}
 
-				result := math.Cos(num.Value)
+				result := math.Cos(num)
+				return &object.Float{Value: result}
+			},
+		},
+		"tan": {
+			Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return &object.Error{Message: "tan() requer 1 argumento"}
+				}
+
+				num, ok := toFloat(args[0])
+				if !ok {
+					return &object.Error{Message: "argumento deve ser um número"}
+				}
+
+				result := math.Tan(num)
 				return &object.Float{Value: result}
 			},
 		},
 		"abs": {
-			Fn: func(args ...object.Object) object.Object {
+			Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
 				if len(args) != 1 {
 					return &object.Error{Message: "abs() requer 1 argumento"}
 				}
@@ -112,9 +132,11 @@ func (s *SMathLibrary) GetBuiltins() map[string]*object.Builtin {
 				switch arg := args[0].(type) {
 				case *object.Integer:
 					if arg.Value < 0 {
-						return &object.Integer{Value: -arg.Value}
+						return object.NewInteger(new(big.Int).Neg(big.NewInt(arg.Value)))
 					}
 					return arg
+				case *object.BigInteger:
+					return object.NewInteger(new(big.Int).Abs(arg.Value))
 				case *object.Float:
 					return &object.Float{Value: math.Abs(arg.Value)}
 				default:
@@ -123,69 +145,25 @@ func (s *SMathLibrary) GetBuiltins() map[string]*object.Builtin {
 			},
 		},
 		"max": {
-			Fn: func(args ...object.Object) object.Object {
+			Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
 				if len(args) != 2 {
 					return &object.Error{Message: "max() requer 2 argumentos"}
 				}
 
-
-				var a, b float64
-
-				switch arg := args[0].(type) {
-				case *object.Integer:
-					a = float64(arg.Value)
-				case *object.Float:
-					a = arg.Value
-				default:
-					return &object.Error{Message: "primeiro argumento deve ser um número"}
-				}
-
-				switch arg := args[1].(type) {
-				case *object.Integer:
-					b = float64(arg.Value)
-				case *object.Float:
-					b = arg.Value
-				default:
-					return &object.Error{Message: "segundo argumento deve ser um número"}
-				}
-
-				result := math.Max(a, b)
-				return &object.Float{Value: result}
+				return extreme(args[0], args[1], 1)
 			},
 		},
 		"min": {
-			Fn: func(args ...object.Object) object.Object {
+			Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
 				if len(args) != 2 {
 					return &object.Error{Message: "min() requer 2 argumentos"}
 				}
 
-
-				var a, b float64
-
-				switch arg := args[0].(type) {
-				case *object.Integer:
-					a = float64(arg.Value)
-				case *object.Float:
-					a = arg.Value
-				default:
-					return &object.Error{Message: "primeiro argumento deve ser um número"}
-				}
-
-				switch arg := args[1].(type) {
-				case *object.Integer:
-					b = float64(arg.Value)
-				case *object.Float:
-					b = arg.Value
-				default:
-					return &object.Error{Message: "segundo argumento deve ser um número"}
-				}
-
-				result := math.Min(a, b)
-				return &object.Float{Value: result}
+				return extreme(args[0], args[1], -1)
 			},
 		},
 		"pi": {
-			Fn: func(args ...object.Object) object.Object {
+			Fn: func(ctx *object.CallContext, args ...object.Object) object.Object {
 				if len(args) != 0 {
 					return &object.Error{Message: "pi() não aceita argumentos"}
 				}
@@ -194,3 +172,56 @@ func (s *SMathLibrary) GetBuiltins() map[string]*object.Builtin {
 		},
 	}
 }
+
+// extreme devolve o maior (sign 1) ou o menor (sign -1) entre dois números,
+// comparando inteiros de forma exata para não perder precisão em BigInteger.
+func extreme(first, second object.Object, sign int) object.Object {
+	a, ok := toFloat(first)
+	if !ok {
+		return &object.Error{Message: "primeiro argumento deve ser um número"}
+	}
+
+	b, ok := toFloat(second)
+	if !ok {
+		return &object.Error{Message: "segundo argumento deve ser um número"}
+	}
+
+	if x, ok := toBigInt(first); ok {
+		if y, ok := toBigInt(second); ok {
+			if x.Cmp(y)*sign >= 0 {
+				return first
+			}
+			return second
+		}
+	}
+
+	if sign > 0 {
+		return &object.Float{Value: math.Max(a, b)}
+	}
+	return &object.Float{Value: math.Min(a, b)}
+}
+
+func toFloat(obj object.Object) (float64, bool) {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return float64(obj.Value), true
+	case *object.BigInteger:
+		value, _ := new(big.Float).SetInt(obj.Value).Float64()
+		return value, true
+	case *object.Float:
+		return obj.Value, true
+	default:
+		return 0, false
+	}
+}
+
+func toBigInt(obj object.Object) (*big.Int, bool) {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return big.NewInt(obj.Value), true
+	case *object.BigInteger:
+		return obj.Value, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,180 @@
+package object
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"sovylang/internal/decimal"
+)
+
+// Equal compara valores estruturalmente: números de tipos diferentes são
+// iguais quando representam o mesmo valor, listas comparam elemento a
+// elemento, registros campo a campo e mapas e conjuntos são comparados sem
+// levar em conta a ordem.
+func Equal(a, b Object) bool {
+	if a == b {
+		return true
+	}
+
+	if cmp, ok := CompareNumbers(a, b); ok {
+		return cmp == 0
+	}
+
+	switch a := a.(type) {
+	case *String:
+		b, ok := b.(*String)
+		return ok && a.Value == b.Value
+	case *Boolean:
+		b, ok := b.(*Boolean)
+		return ok && a.Value == b.Value
+	case *Null:
+		_, ok := b.(*Null)
+		return ok
+	case *Array:
+		b, ok := b.(*Array)
+		if !ok || len(a.Elements) != len(b.Elements) {
+			return false
+		}
+		for i := range a.Elements {
+			if !Equal(a.Elements[i], b.Elements[i]) {
+				return false
+			}
+		}
+		return true
+	case *Tuple:
+		b, ok := b.(*Tuple)
+		if !ok || len(a.Elements) != len(b.Elements) {
+			return false
+		}
+		for i := range a.Elements {
+			if !Equal(a.Elements[i], b.Elements[i]) {
+				return false
+			}
+		}
+		return true
+	case *Set:
+		b, ok := b.(*Set)
+		if !ok || a.Len() != b.Len() {
+			return false
+		}
+		for _, e := range a.Elements() {
+			if !b.Contains(e) {
+				return false
+			}
+		}
+		return true
+	case *Record:
+		b, ok := b.(*Record)
+		if !ok || a.RecordType != b.RecordType {
+			return false
+		}
+		for i := range a.Values {
+			if !Equal(a.Values[i], b.Values[i]) {
+				return false
+			}
+		}
+		return true
+	case *Hash:
+		b, ok := b.(*Hash)
+		if !ok || a.Len() != b.Len() {
+			return false
+		}
+		for _, pair := range a.Pairs() {
+			other, ok := b.Get(pair.Key)
+			if !ok || !Equal(pair.Value, other.Value) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// CompareNumbers ordena dois valores numéricos de quaisquer tipos. O segundo
+// retorno é falso quando algum dos valores não é número (ou é NaN).
+func CompareNumbers(a, b Object) (int, bool) {
+	if !IsNumber(a) || !IsNumber(b) {
+		return 0, false
+	}
+
+	if x, ok := a.(*Integer); ok {
+		if y, ok := b.(*Integer); ok {
+			switch {
+			case x.Value < y.Value:
+				return -1, true
+			case x.Value > y.Value:
+				return 1, true
+			default:
+				return 0, true
+			}
+		}
+	}
+
+	if a.Type() == DECIMAL_OBJ || b.Type() == DECIMAL_OBJ {
+		x, err := ToDecimal(a)
+		if err != nil {
+			return 0, false
+		}
+		y, err := ToDecimal(b)
+		if err != nil {
+			return 0, false
+		}
+		return x.Cmp(y), true
+	}
+
+	x, ok := toBigFloat(a)
+	if !ok {
+		return 0, false
+	}
+	y, ok := toBigFloat(b)
+	if !ok {
+		return 0, false
+	}
+	return x.Cmp(y), true
+}
+
+// IsNumber diz se obj é um número de qualquer tipo.
+func IsNumber(obj Object) bool {
+	switch obj.Type() {
+	case INTEGER_OBJ, BIG_INTEGER_OBJ, FLOAT_OBJ, DECIMAL_OBJ:
+		return true
+	default:
+		return false
+	}
+}
+
+// ToDecimal converte um número, ou um texto como "10.50", para decimal.
+func ToDecimal(obj Object) (decimal.Decimal, error) {
+	switch obj := obj.(type) {
+	case *Decimal:
+		return obj.Value, nil
+	case *Integer:
+		return decimal.FromInt(big.NewInt(obj.Value)), nil
+	case *BigInteger:
+		return decimal.FromInt(obj.Value), nil
+	case *Float:
+		return decimal.FromFloat(obj.Value)
+	case *String:
+		return decimal.Parse(obj.Value)
+	default:
+		return decimal.Decimal{}, fmt.Errorf("não é possível converter %s para decimal", obj.Type())
+	}
+}
+
+func toBigFloat(obj Object) (*big.Float, bool) {
+	switch obj := obj.(type) {
+	case *Integer:
+		return new(big.Float).SetInt64(obj.Value), true
+	case *BigInteger:
+		return new(big.Float).SetInt(obj.Value), true
+	case *Float:
+		if math.IsNaN(obj.Value) {
+			return nil, false
+		}
+		return big.NewFloat(obj.Value), true
+	default:
+		return nil, false
+	}
+}
@@ -1,30 +1,174 @@
 package object
 
+import "sovylang/internal/ast"
+
 func NewEnvironment() *Environment {
-	s := make(map[string]Object)
-	return &Environment{store: s, outer: nil}
+	return &Environment{}
 }
 
 func NewEnclosedEnvironment(outer *Environment) *Environment {
-	env := NewEnvironment()
-	env.outer = outer
-	return env
+	return &Environment{outer: outer}
+}
+
+// NewBlockEnvironment cria o escopo de um bloco se, para ou caso, que
+// pertence à mesma função do escopo externo.
+func NewBlockEnvironment(outer *Environment) *Environment {
+	return &Environment{outer: outer, block: true}
 }
 
+// Environment é um quadro de variáveis. Os nomes da disposição calculada
+// pelo resolvedor ficam em values, uma posição por nome (nil enquanto não
+// declarado); os demais, em store.
 type Environment struct {
-	store map[string]Object
-	outer *Environment
+	scope     *ast.Scope
+	values    []Object
+	store     map[string]Object
+	constants map[string]bool
+	outer     *Environment
+	block     bool
 }
 
-func (e *Environment) Get(name string) (Object, bool) {
-	value, ok := e.store[name]
-	if !ok && e.outer != nil {
-		value, ok = e.outer.Get(name)
+// WithScope dá ao ambiente a disposição scope. Deve ser chamado antes de
+// qualquer ligação; scope nil mantém só as ligações por nome.
+func (e *Environment) WithScope(scope *ast.Scope) *Environment {
+	if scope != nil {
+		e.scope = scope
+		e.values = make([]Object, len(scope.Names))
 	}
+	return e
+}
+
+// own procura name só neste quadro.
+func (e *Environment) own(name string) (Object, bool) {
+	if e.scope != nil {
+		if slot, ok := e.scope.Slots[name]; ok {
+			value := e.values[slot]
+			return value, value != nil
+		}
+	}
+	value, ok := e.store[name]
 	return value, ok
 }
 
+func (e *Environment) Get(name string) (Object, bool) {
+	for env := e; env != nil; env = env.outer {
+		if value, ok := env.own(name); ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// GetAt lê a posição slot do quadro depth níveis acima, como calculado pelo
+// resolvedor. Se a posição ainda não foi preenchida (o nome é declarado mais
+// adiante no escopo), name é procurado nos quadros externos, como em Get.
+func (e *Environment) GetAt(depth, slot int, name string) (Object, bool) {
+	env := e.ancestor(depth)
+	if env == nil || slot >= len(env.values) {
+		return e.Get(name)
+	}
+	if value := env.values[slot]; value != nil {
+		return value, true
+	}
+	if env.outer == nil {
+		return nil, false
+	}
+	return env.outer.Get(name)
+}
+
+func (e *Environment) ancestor(depth int) *Environment {
+	env := e
+	for i := 0; i < depth && env != nil; i++ {
+		env = env.outer
+	}
+	return env
+}
+
 func (e *Environment) Set(name string, val Object) Object {
-	e.store[name] = val
+	e.bind(name, val)
+	delete(e.constants, name)
+	return val
+}
+
+// SetConstant liga name a val como somente leitura.
+func (e *Environment) SetConstant(name string, val Object) Object {
+	e.bind(name, val)
+	if e.constants == nil {
+		e.constants = map[string]bool{}
+	}
+	e.constants[name] = true
 	return val
 }
+
+func (e *Environment) bind(name string, val Object) {
+	if e.scope != nil {
+		if slot, ok := e.scope.Slots[name]; ok {
+			e.values[slot] = val
+			return
+		}
+	}
+	if e.store == nil {
+		e.store = map[string]Object{}
+	}
+	e.store[name] = val
+}
+
+// IsConstant diz se a ligação visível de name é uma constante.
+func (e *Environment) IsConstant(name string) bool {
+	for env := e; env != nil; env = env.outer {
+		if _, ok := env.own(name); ok {
+			return env.constants[name]
+		}
+	}
+	return false
+}
+
+// HasOwn diz se name está ligado neste escopo, sem olhar os externos.
+func (e *Environment) HasOwn(name string) bool {
+	_, ok := e.own(name)
+	return ok
+}
+
+// DeclaredInFunction diz se name está ligado neste escopo ou em algum bloco
+// externo até o escopo da função (ou do programa) que os contém.
+func (e *Environment) DeclaredInFunction(name string) bool {
+	for env := e; env != nil; env = env.outer {
+		if _, ok := env.own(name); ok {
+			return true
+		}
+		if !env.block {
+			return false
+		}
+	}
+	return false
+}
+
+// IsGlobal diz se este é o escopo mais externo do programa.
+func (e *Environment) IsGlobal() bool {
+	return e.outer == nil
+}
+
+func (e *Environment) Assign(name string, val Object) bool {
+	for env := e; env != nil; env = env.outer {
+		if _, ok := env.own(name); ok {
+			env.bind(name, val)
+			return true
+		}
+	}
+	return false
+}
+
+// AssignAt altera a posição resolvida slot do quadro depth níveis acima.
+// found é falso se a posição ainda não foi declarada, e constant indica que
+// ela guarda uma constante (nesse caso nada é alterado).
+func (e *Environment) AssignAt(depth, slot int, name string, val Object) (found, constant bool) {
+	env := e.ancestor(depth)
+	if env == nil || slot >= len(env.values) || env.values[slot] == nil {
+		return false, false
+	}
+	if env.constants[name] {
+		return true, true
+	}
+	env.values[slot] = val
+	return true, false
+}
@@ -2,26 +2,43 @@ package object
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
 	"hash/fnv"
+	"math"
+	"math/big"
 	"sovylang/internal/ast"
+	"sovylang/internal/decimal"
+	"strconv"
 	"strings"
 )
 
 type ObjectType string
 
 const (
-	INTEGER_OBJ  = "INTEGER"
-	FLOAT_OBJ    = "FLOAT"
-	BOOLEAN_OBJ  = "BOOLEAN"
-	STRING_OBJ   = "STRING"
-	NULL_OBJ     = "NULL"
-	RETURN_OBJ   = "RETURN_VALUE"
-	ERROR_OBJ    = "ERROR"
-	FUNCTION_OBJ = "FUNCTION"
-	BUILTIN_OBJ  = "BUILTIN"
-	ARRAY_OBJ    = "ARRAY"
-	HASH_OBJ     = "HASH"
+	INTEGER_OBJ      = "INTEGER"
+	BIG_INTEGER_OBJ  = "BIG_INTEGER"
+	FLOAT_OBJ        = "FLOAT"
+	DECIMAL_OBJ      = "DECIMAL"
+	BOOLEAN_OBJ      = "BOOLEAN"
+	STRING_OBJ       = "STRING"
+	NULL_OBJ         = "NULL"
+	RETURN_OBJ       = "RETURN_VALUE"
+	ERROR_OBJ        = "ERROR"
+	FUNCTION_OBJ     = "FUNCTION"
+	BUILTIN_OBJ      = "BUILTIN"
+	ARRAY_OBJ        = "ARRAY"
+	HASH_OBJ         = "HASH"
+	TUPLE_OBJ        = "TUPLE"
+	SET_OBJ          = "SET"
+	RECORD_TYPE_OBJ  = "RECORD_TYPE"
+	RECORD_OBJ       = "RECORD"
+	CLASS_OBJ        = "CLASS"
+	INSTANCE_OBJ     = "INSTANCE"
+	BOUND_METHOD_OBJ = "BOUND_METHOD"
+	SUPER_OBJ        = "SUPER"
+	ENUM_OBJ         = "ENUM"
+	ENUM_MEMBER_OBJ  = "ENUM_MEMBER"
 )
 
 type Object interface {
@@ -36,6 +53,22 @@ type Integer struct {
 func (i *Integer) Inspect() string  { return fmt.Sprintf("%d", i.Value) }
 func (i *Integer) Type() ObjectType { return INTEGER_OBJ }
 
+// BigInteger guarda inteiros que não cabem em int64. Resultados que voltam a
+// caber em int64 são sempre convertidos de volta para Integer por NewInteger.
+type BigInteger struct {
+	Value *big.Int
+}
+
+func (bi *BigInteger) Inspect() string  { return bi.Value.String() }
+func (bi *BigInteger) Type() ObjectType { return BIG_INTEGER_OBJ }
+
+func NewInteger(value *big.Int) Object {
+	if value.IsInt64() {
+		return &Integer{Value: value.Int64()}
+	}
+	return &BigInteger{Value: value}
+}
+
 type Float struct {
 	Value float64
 }
@@ -43,6 +76,13 @@ type Float struct {
 func (f *Float) Inspect() string  { return fmt.Sprintf("%g", f.Value) }
 func (f *Float) Type() ObjectType { return FLOAT_OBJ }
 
+type Decimal struct {
+	Value decimal.Decimal
+}
+
+func (d *Decimal) Inspect() string  { return d.Value.String() }
+func (d *Decimal) Type() ObjectType { return DECIMAL_OBJ }
+
 type Boolean struct {
 	Value bool
 }
@@ -65,7 +105,7 @@ func (s *String) Inspect() string  { return s.Value }
 type Null struct{}
 
 func (n *Null) Type() ObjectType { return NULL_OBJ }
-func (n *Null) Inspect() string  { return "null" }
+func (n *Null) Inspect() string  { return "nulo" }
 
 type ReturnValue struct {
 	Value Object
@@ -82,7 +122,9 @@ func (e *Error) Type() ObjectType { return ERROR_OBJ }
 func (e *Error) Inspect() string  { return "ERRO: " + e.Message }
 
 type Function struct {
-	Parameters []*ast.Identifier
+	Name       string
+	Parameters []*ast.Parameter
+	ReturnType string
 	Body       *ast.BlockStatement
 	Env        *Environment
 }
@@ -97,14 +139,30 @@ func (f *Function) Inspect() string {
 	out.WriteString("função")
 	out.WriteString("(")
 	out.WriteString(strings.Join(params, ", "))
-	out.WriteString(") {\n")
+	out.WriteString(")")
+	if f.ReturnType != "" {
+		out.WriteString(" -> " + f.ReturnType)
+	}
+	out.WriteString(" {\n")
 	out.WriteString(f.Body.String())
 	out.WriteString("\n}")
 	return out.String()
 }
 
+// CallContext acompanha cada chamada a uma função built-in: o ambiente e a
+// posição de quem chamou, e Call para invocar funções da linguagem (por
+// exemplo, callbacks passados a mapear).
+type CallContext struct {
+	Env    *Environment
+	Line   int
+	Column int
+	Call   func(fn Object, args ...Object) Object
+}
+
+type BuiltinFunction func(ctx *CallContext, args ...Object) Object
+
 type Builtin struct {
-	Fn func(args ...Object) Object
+	Fn BuiltinFunction
 }
 
 func (b *Builtin) Type() ObjectType { return BUILTIN_OBJ }
@@ -127,6 +185,28 @@ func (ao *Array) Inspect() string {
 	return out.String()
 }
 
+type Tuple struct {
+	Elements []Object
+}
+
+func (t *Tuple) Type() ObjectType { return TUPLE_OBJ }
+func (t *Tuple) Inspect() string {
+	var out bytes.Buffer
+	elements := []string{}
+	for _, e := range t.Elements {
+		elements = append(elements, e.Inspect())
+	}
+	out.WriteString("(")
+	out.WriteString(strings.Join(elements, ", "))
+	if len(elements) == 1 {
+		out.WriteString(",")
+	}
+	out.WriteString(")")
+	return out.String()
+}
+
+// HashKey é apenas um resumo da chave: valores diferentes podem ter o mesmo
+// HashKey, por isso Hash sempre confirma a igualdade com Equal.
 type HashKey struct {
 	Type  ObjectType
 	Value uint64
@@ -143,7 +223,36 @@ func (b *Boolean) HashKey() HashKey {
 }
 
 func (i *Integer) HashKey() HashKey {
-	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
+	return HashKey{Type: INTEGER_OBJ, Value: uint64(i.Value)}
+}
+
+func (bi *BigInteger) HashKey() HashKey {
+	return hashBigInt(bi.Value)
+}
+
+// Os números usam a mesma família de HashKey independentemente do tipo,
+// para que 1, 1.0 e decimal("1.00") sejam a mesma chave, assim como são
+// iguais em Equal.
+func (f *Float) HashKey() HashKey {
+	if f.Value == math.Trunc(f.Value) && !math.IsInf(f.Value, 0) {
+		if f.Value >= math.MinInt64 && f.Value < math.MaxInt64 {
+			return HashKey{Type: INTEGER_OBJ, Value: uint64(int64(f.Value))}
+		}
+		integer, _ := big.NewFloat(f.Value).Int(nil)
+		return hashBigInt(integer)
+	}
+	return hashFraction(strconv.FormatFloat(f.Value, 'f', -1, 64))
+}
+
+func (d *Decimal) HashKey() HashKey {
+	if d.Value.IsInteger() {
+		integer := d.Value.Integer()
+		if integer.IsInt64() {
+			return HashKey{Type: INTEGER_OBJ, Value: uint64(integer.Int64())}
+		}
+		return hashBigInt(integer)
+	}
+	return hashFraction(strings.TrimRight(d.Value.String(), "0"))
 }
 
 func (s *String) HashKey() HashKey {
@@ -152,20 +261,145 @@ func (s *String) HashKey() HashKey {
 	return HashKey{Type: s.Type(), Value: h.Sum64()}
 }
 
+func (n *Null) HashKey() HashKey {
+	return HashKey{Type: n.Type()}
+}
+
+func (t *Tuple) HashKey() HashKey {
+	h := fnv.New64a()
+	var buf [8]byte
+	for _, e := range t.Elements {
+		key := e.(Hashable).HashKey()
+		h.Write([]byte(key.Type))
+		binary.LittleEndian.PutUint64(buf[:], key.Value)
+		h.Write(buf[:])
+	}
+	return HashKey{Type: t.Type(), Value: h.Sum64()}
+}
+
+func hashBigInt(value *big.Int) HashKey {
+	h := fnv.New64a()
+	h.Write(value.Bytes())
+	if value.Sign() < 0 {
+		h.Write([]byte{'-'})
+	}
+	return HashKey{Type: INTEGER_OBJ, Value: h.Sum64()}
+}
+
+func hashFraction(digits string) HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(digits))
+	return HashKey{Type: FLOAT_OBJ, Value: h.Sum64()}
+}
+
+type Hashable interface {
+	Object
+	HashKey() HashKey
+}
+
+// IsHashable informa se o valor pode ser usado como chave de mapa. Listas e
+// mapas são mutáveis e não podem; tuplas podem quando todos os seus
+// elementos também podem.
+func IsHashable(obj Object) bool {
+	if tuple, ok := obj.(*Tuple); ok {
+		for _, e := range tuple.Elements {
+			if !IsHashable(e) {
+				return false
+			}
+		}
+		return true
+	}
+	_, ok := obj.(Hashable)
+	return ok
+}
+
 type HashPair struct {
 	Key   Object
 	Value Object
 }
 
+// Hash preserva a ordem de inserção das chaves, de modo que Inspect, a
+// iteração e a saída de programas são determinísticas. As chaves devem
+// satisfazer IsHashable.
 type Hash struct {
-	Pairs map[HashKey]HashPair
+	buckets map[HashKey][]*HashPair
+	order   []*HashPair
+}
+
+func NewHash() *Hash {
+	return &Hash{buckets: make(map[HashKey][]*HashPair)}
+}
+
+func (h *Hash) lookup(key Object) (*HashPair, HashKey) {
+	hashKey := key.(Hashable).HashKey()
+	for _, pair := range h.buckets[hashKey] {
+		if Equal(pair.Key, key) {
+			return pair, hashKey
+		}
+	}
+	return nil, hashKey
+}
+
+func (h *Hash) Get(key Object) (HashPair, bool) {
+	pair, _ := h.lookup(key)
+	if pair == nil {
+		return HashPair{}, false
+	}
+	return *pair, true
+}
+
+// Set substitui o valor de uma chave existente sem alterar sua posição.
+func (h *Hash) Set(key, value Object) {
+	pair, hashKey := h.lookup(key)
+	if pair != nil {
+		pair.Value = value
+		return
+	}
+
+	pair = &HashPair{Key: key, Value: value}
+	h.buckets[hashKey] = append(h.buckets[hashKey], pair)
+	h.order = append(h.order, pair)
+}
+
+func (h *Hash) Delete(key Object) (HashPair, bool) {
+	pair, hashKey := h.lookup(key)
+	if pair == nil {
+		return HashPair{}, false
+	}
+
+	h.buckets[hashKey] = removePair(h.buckets[hashKey], pair)
+	if len(h.buckets[hashKey]) == 0 {
+		delete(h.buckets, hashKey)
+	}
+	h.order = removePair(h.order, pair)
+
+	return *pair, true
+}
+
+func removePair(pairs []*HashPair, pair *HashPair) []*HashPair {
+	for i, p := range pairs {
+		if p == pair {
+			return append(pairs[:i], pairs[i+1:]...)
+		}
+	}
+	return pairs
+}
+
+func (h *Hash) Len() int { return len(h.order) }
+
+func (h *Hash) Pairs() []HashPair {
+	pairs := make([]HashPair, 0, len(h.order))
+	for _, pair := range h.order {
+		pairs = append(pairs, *pair)
+	}
+	return pairs
 }
 
 func (h *Hash) Type() ObjectType { return HASH_OBJ }
 func (h *Hash) Inspect() string {
 	var out bytes.Buffer
 	pairs := []string{}
-	for _, pair := range h.Pairs {
+	for _, pair := range h.Pairs() {
 		pairs = append(pairs, fmt.Sprintf("%s: %s",
 			pair.Key.Inspect(), pair.Value.Inspect()))
 	}
@@ -175,6 +409,214 @@ func (h *Hash) Inspect() string {
 	return out.String()
 }
 
-type Hashable interface {
-	HashKey() HashKey
+// Set guarda valores sem repetição, na ordem em que foram adicionados. Usa
+// um Hash internamente, então os elementos devem satisfazer IsHashable.
+type Set struct {
+	items *Hash
+}
+
+func NewSet() *Set {
+	return &Set{items: NewHash()}
+}
+
+// Add devolve falso quando o valor já fazia parte do conjunto.
+func (s *Set) Add(value Object) bool {
+	if s.Contains(value) {
+		return false
+	}
+	s.items.Set(value, value)
+	return true
+}
+
+func (s *Set) Contains(value Object) bool {
+	_, ok := s.items.Get(value)
+	return ok
+}
+
+func (s *Set) Remove(value Object) bool {
+	_, ok := s.items.Delete(value)
+	return ok
+}
+
+func (s *Set) Len() int { return s.items.Len() }
+
+func (s *Set) Elements() []Object {
+	pairs := s.items.Pairs()
+	elements := make([]Object, len(pairs))
+	for i, pair := range pairs {
+		elements[i] = pair.Key
+	}
+	return elements
+}
+
+func (s *Set) Type() ObjectType { return SET_OBJ }
+func (s *Set) Inspect() string {
+	var out bytes.Buffer
+	elements := []string{}
+	for _, e := range s.Elements() {
+		elements = append(elements, e.Inspect())
+	}
+	out.WriteString("conjunto{")
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString("}")
+	return out.String()
+}
+
+type RecordField struct {
+	Name    string
+	Type    string
+	Default ast.Expression
+	// Env é onde Default é avaliado em classes: o ambiente da classe que
+	// declarou o campo, mesmo quando ele é herdado.
+	Env *Environment
+}
+
+// RecordType é o valor criado por uma declaração "registro". Chamá-lo
+// constrói um Record; os valores padrão são avaliados em Env a cada chamada.
+type RecordType struct {
+	Name   string
+	Fields []RecordField
+	Env    *Environment
+}
+
+func (rt *RecordType) Type() ObjectType { return RECORD_TYPE_OBJ }
+func (rt *RecordType) Inspect() string  { return "registro " + rt.Name }
+
+func (rt *RecordType) FieldIndex(name string) (int, bool) {
+	for i, field := range rt.Fields {
+		if field.Name == name {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// Record guarda os valores na mesma ordem dos campos de seu RecordType.
+type Record struct {
+	RecordType *RecordType
+	Values     []Object
+}
+
+func (r *Record) Type() ObjectType { return RECORD_OBJ }
+func (r *Record) Inspect() string {
+	var out bytes.Buffer
+	fields := []string{}
+	for i, field := range r.RecordType.Fields {
+		fields = append(fields, field.Name+": "+r.Values[i].Inspect())
+	}
+	out.WriteString(r.RecordType.Name)
+	out.WriteString("(")
+	out.WriteString(strings.Join(fields, ", "))
+	out.WriteString(")")
+	return out.String()
+}
+
+// Class é o valor criado por uma declaração "classe". Fields já inclui os
+// campos herdados, antes dos campos próprios.
+type Class struct {
+	Name       string
+	Superclass *Class
+	Fields     []RecordField
+	Methods    map[string]*Function
+	Env        *Environment
+}
+
+func (c *Class) Type() ObjectType { return CLASS_OBJ }
+func (c *Class) Inspect() string  { return "classe " + c.Name }
+
+func (c *Class) FieldIndex(name string) (int, bool) {
+	for i, field := range c.Fields {
+		if field.Name == name {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// FindMethod procura o método na classe e em suas superclasses e devolve
+// também a classe onde ele foi definido, usada para resolver "super".
+func (c *Class) FindMethod(name string) (*Function, *Class) {
+	for class := c; class != nil; class = class.Superclass {
+		if method, ok := class.Methods[name]; ok {
+			return method, class
+		}
+	}
+	return nil, nil
+}
+
+type Instance struct {
+	Class  *Class
+	Values []Object
+}
+
+func (i *Instance) Type() ObjectType { return INSTANCE_OBJ }
+func (i *Instance) Inspect() string {
+	var out bytes.Buffer
+	fields := []string{}
+	for idx, field := range i.Class.Fields {
+		fields = append(fields, field.Name+": "+i.Values[idx].Inspect())
+	}
+	out.WriteString(i.Class.Name)
+	out.WriteString("(")
+	out.WriteString(strings.Join(fields, ", "))
+	out.WriteString(")")
+	return out.String()
+}
+
+// BoundMethod é um método já associado à instância que será "este" quando
+// for chamado.
+type BoundMethod struct {
+	Receiver *Instance
+	Method   *Function
+	Owner    *Class
+	Name     string
+}
+
+func (bm *BoundMethod) Type() ObjectType { return BOUND_METHOD_OBJ }
+func (bm *BoundMethod) Inspect() string {
+	return "método " + bm.Owner.Name + "." + bm.Name
+}
+
+// Super é o valor de "super" dentro de um método: procura métodos a partir
+// de Class, a superclasse da classe onde o método foi definido.
+type Super struct {
+	Receiver *Instance
+	Class    *Class
+}
+
+func (s *Super) Type() ObjectType { return SUPER_OBJ }
+func (s *Super) Inspect() string  { return "super " + s.Class.Name }
+
+// Enum é o valor criado por uma declaração "enumeracao". Cada membro existe
+// uma única vez, então membros são comparados por identidade.
+type Enum struct {
+	Name    string
+	Members []*EnumMember
+}
+
+func (en *Enum) Type() ObjectType { return ENUM_OBJ }
+func (en *Enum) Inspect() string  { return "enumeracao " + en.Name }
+
+func (en *Enum) Member(name string) (*EnumMember, bool) {
+	for _, member := range en.Members {
+		if member.Name == name {
+			return member, true
+		}
+	}
+	return nil, false
+}
+
+type EnumMember struct {
+	Enum    *Enum
+	Name    string
+	Ordinal int
+}
+
+func (em *EnumMember) Type() ObjectType { return ENUM_MEMBER_OBJ }
+func (em *EnumMember) Inspect() string  { return em.Enum.Name + "." + em.Name }
+
+func (em *EnumMember) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(em.Inspect()))
+	return HashKey{Type: em.Type(), Value: h.Sum64()}
 }
@@ -1,7 +1,9 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
+	"math/big"
 	"sovylang/internal/ast"
 	"sovylang/internal/lexer"
 	"sovylang/internal/token"
@@ -11,8 +13,15 @@ import (
 const (
 	_ int = iota
 	LOWEST
+	COALESCE
+	LOGICAL_OR
+	LOGICAL_AND
 	EQUALS
 	LESSGREATER
+	BIT_OR
+	BIT_XOR
+	BIT_AND
+	SHIFT
 	SUM
 	PRODUCT
 	PREFIX
@@ -21,21 +30,40 @@ const (
 )
 
 var precedences = map[token.TokenType]int{
-	token.EQ:       EQUALS,
-	token.NOT_EQ:   EQUALS,
-	token.LT:       LESSGREATER,
-	token.GT:       LESSGREATER,
-	token.LTE:      LESSGREATER,
-	token.GTE:      LESSGREATER,
-	token.PLUS:     SUM,
-	token.MINUS:    SUM,
-	token.SLASH:    PRODUCT,
-	token.ASTERISK: PRODUCT,
-	token.PERCENT:  PRODUCT,
-	token.LPAREN:   CALL,
-	token.LBRACKET: INDEX,
-	token.E:        EQUALS,
-	token.OU:       EQUALS,
+	token.EQ:             EQUALS,
+	token.NOT_EQ:         EQUALS,
+	token.LT:             LESSGREATER,
+	token.GT:             LESSGREATER,
+	token.LTE:            LESSGREATER,
+	token.GTE:            LESSGREATER,
+	token.PIPE:           BIT_OR,
+	token.CARET:          BIT_XOR,
+	token.AMPERSAND:      BIT_AND,
+	token.SHIFT_LEFT:     SHIFT,
+	token.SHIFT_RIGHT:    SHIFT,
+	token.PLUS:           SUM,
+	token.MINUS:          SUM,
+	token.SLASH:          PRODUCT,
+	token.ASTERISK:       PRODUCT,
+	token.PERCENT:        PRODUCT,
+	token.LPAREN:         CALL,
+	token.LBRACKET:       INDEX,
+	token.E:              LOGICAL_AND,
+	token.OU:             LOGICAL_OR,
+	token.COALESCE:       COALESCE,
+	token.É:              EQUALS,
+	token.EM:             EQUALS,
+	token.OPTIONAL_INDEX: INDEX,
+	token.DOT:            INDEX,
+}
+
+// Precedence devolve a precedência do operador binário t na análise, ou
+// LOWEST se t não for um operador.
+func Precedence(t token.TokenType) int {
+	if p, ok := precedences[t]; ok {
+		return p
+	}
+	return LOWEST
 }
 
 type (
@@ -68,17 +96,23 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
 	p.registerPrefix(token.VERDADEIRO, p.parseBoolean)
 	p.registerPrefix(token.FALSO, p.parseBoolean)
+	p.registerPrefix(token.NULO, p.parseNullLiteral)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
+	p.registerPrefix(token.TILDE, p.parsePrefixExpression)
 	p.registerPrefix(token.NAO, p.parsePrefixExpression)
 	p.registerPrefix(token.NÃO, p.parsePrefixExpression)
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
 	p.registerPrefix(token.SE, p.parseIfExpression)
+	p.registerPrefix(token.ESCOLHA, p.parseSwitchExpression)
 	p.registerPrefix(token.FUNÇÃO, p.parseFunctionLiteral)
 	p.registerPrefix(token.FUNCAO, p.parseFunctionLiteral)
 	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
 	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
+	p.registerPrefix(token.CONJUNTO, p.parseSetLiteral)
 	p.registerPrefix(token.IMPRIMIR, p.parseImprimirCall)
+	p.registerPrefix(token.ESTE, p.parseIdentifier)
+	p.registerPrefix(token.SUPER, p.parseIdentifier)
 
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
 	p.registerInfix(token.PLUS, p.parseInfixExpression)
@@ -86,16 +120,26 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.SLASH, p.parseInfixExpression)
 	p.registerInfix(token.ASTERISK, p.parseInfixExpression)
 	p.registerInfix(token.PERCENT, p.parseInfixExpression)
+	p.registerInfix(token.AMPERSAND, p.parseInfixExpression)
+	p.registerInfix(token.PIPE, p.parseInfixExpression)
+	p.registerInfix(token.CARET, p.parseInfixExpression)
+	p.registerInfix(token.SHIFT_LEFT, p.parseInfixExpression)
+	p.registerInfix(token.SHIFT_RIGHT, p.parseInfixExpression)
 	p.registerInfix(token.EQ, p.parseInfixExpression)
 	p.registerInfix(token.NOT_EQ, p.parseInfixExpression)
 	p.registerInfix(token.LT, p.parseInfixExpression)
 	p.registerInfix(token.GT, p.parseInfixExpression)
 	p.registerInfix(token.LTE, p.parseInfixExpression)
 	p.registerInfix(token.GTE, p.parseInfixExpression)
-	p.registerInfix(token.E, p.parseInfixExpression)
-	p.registerInfix(token.OU, p.parseInfixExpression)
+	p.registerInfix(token.E, p.parseLogicalExpression)
+	p.registerInfix(token.OU, p.parseLogicalExpression)
+	p.registerInfix(token.COALESCE, p.parseLogicalExpression)
+	p.registerInfix(token.É, p.parseInfixExpression)
+	p.registerInfix(token.EM, p.parseInfixExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
 	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
+	p.registerInfix(token.OPTIONAL_INDEX, p.parseIndexExpression)
+	p.registerInfix(token.DOT, p.parseMemberExpression)
 
 
 	p.nextToken()
@@ -140,6 +184,20 @@ func (p *Parser) parseStatement() ast.Statement {
 	switch p.curToken.Type {
 	case token.NUMERO, token.TEXTO, token.BOOLEANO, token.LISTA, token.MAPA:
 		return p.parseVarStatement()
+	case token.CONJUNTO:
+		// "conjunto" também inicia expressões: conjunto{...} e conjunto(...)
+		if p.peekTokenIs(token.IDENT) {
+			return p.parseVarStatement()
+		}
+		return p.parseExpressionStatement()
+	case token.REGISTRO:
+		return p.parseRecordStatement()
+	case token.CLASSE:
+		return p.parseClassStatement()
+	case token.ENUMERACAO, token.ENUMERAÇÃO:
+		return p.parseEnumStatement()
+	case token.CONSTANTE:
+		return p.parseConstStatement()
 	case token.RETORNE:
 		return p.parseReturnStatement()
 	case token.PARA:
@@ -151,13 +209,22 @@ func (p *Parser) parseStatement() ast.Statement {
 		if p.curToken.Literal == "sovy" {
 			return p.parseIncludeStatement()
 		}
+		// declaração com o nome de um registro como tipo: Pessoa p = ...
+		if p.peekTokenIs(token.IDENT) {
+			return p.parseVarStatement()
+		}
 		return p.parseExpressionStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
 }
 
-func (p *Parser) parseVarStatement() *ast.VarStatement {
+func (p *Parser) parseVarStatement() ast.Statement {
+	if (p.curTokenIs(token.LISTA) && p.peekTokenIs(token.LBRACKET)) ||
+		(p.curTokenIs(token.MAPA) && p.peekTokenIs(token.LBRACE)) {
+		return p.parseDestructureStatement()
+	}
+
 	stmt := &ast.VarStatement{Token: p.curToken, Type: p.curToken.Literal}
 
 	if !p.expectPeek(token.IDENT) {
@@ -181,12 +248,109 @@ func (p *Parser) parseVarStatement() *ast.VarStatement {
 	return stmt
 }
 
+// parseConstStatement lê "constante NOME = valor" ou, com tipo,
+// "constante numero NOME = valor".
+func (p *Parser) parseConstStatement() ast.Statement {
+	stmt := &ast.VarStatement{Token: p.curToken, Constant: true}
+
+	p.nextToken()
+	if isTypeName(p.curToken) && p.peekTokenIs(token.IDENT) {
+		stmt.Type = p.curToken.Literal
+		p.nextToken()
+	}
+	if !p.curTokenIs(token.IDENT) {
+		p.errors = append(p.errors, fmt.Sprintf("esperado nome da constante, recebido %s", p.curToken.Literal))
+		return nil
+	}
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if !p.expectPeek(token.ASSIGN) {
+		return nil
+	}
+	p.nextToken()
+
+	stmt.Value = p.parseExpression(LOWEST)
+	return stmt
+}
+
+func (p *Parser) parseDestructureStatement() ast.Statement {
+	stmt := &ast.DestructureStatement{Token: p.curToken}
+
+	end := token.TokenType(token.RBRACKET)
+	if stmt.IsMap() {
+		end = token.RBRACE
+	}
+	p.nextToken()
+
+	for !p.peekTokenIs(end) {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Names = append(stmt.Names, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.expectPeek(end) {
+		return nil
+	}
+	if len(stmt.Names) == 0 {
+		p.errors = append(p.errors, "desestruturação sem nomes")
+		return nil
+	}
+	if !p.expectPeek(token.ASSIGN) {
+		return nil
+	}
+
+	p.nextToken()
+	stmt.Value = p.parseExpressionOrTuple(LOWEST)
+
+	for p.peekToken.Type == token.SEMICOLON {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// parseExpressionOrTuple lê "a, b, c" fora de parênteses, como em
+// "retorne a, b" e "a, b = b, a". Mais de uma expressão vira uma tupla.
+func (p *Parser) parseExpressionOrTuple(precedence int) ast.Expression {
+	tok := p.curToken
+	first := p.parseExpression(precedence)
+	if !p.peekTokenIs(token.COMMA) {
+		return first
+	}
+	return p.parseTupleRest(tok, first, precedence)
+}
+
+// parseTupleRest continua uma lista sem parênteses depois do primeiro
+// elemento, quando o próximo token é uma vírgula. Devolve nil se algum
+// elemento não puder ser lido; o erro já foi registrado.
+func (p *Parser) parseTupleRest(tok token.Token, first ast.Expression, precedence int) ast.Expression {
+	if first == nil {
+		return nil
+	}
+	tuple := &ast.TupleLiteral{Token: tok, Elements: []ast.Expression{first}}
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		p.nextToken()
+		element := p.parseExpression(precedence)
+		if element == nil {
+			return nil
+		}
+		tuple.Elements = append(tuple.Elements, element)
+	}
+	return tuple
+}
+
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	stmt := &ast.ReturnStatement{Token: p.curToken}
 
 	p.nextToken()
 
-	stmt.ReturnValue = p.parseExpression(LOWEST)
+	stmt.ReturnValue = p.parseExpressionOrTuple(LOWEST)
 
 	for p.peekToken.Type == token.SEMICOLON {
 		p.nextToken()
@@ -195,7 +359,11 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	return stmt
 }
 
-func (p *Parser) parseForStatement() *ast.ForStatement {
+func (p *Parser) parseForStatement() ast.Statement {
+	if p.peekTokenIs(token.CADA) {
+		return p.parseForEachStatement()
+	}
+
 	stmt := &ast.ForStatement{Token: p.curToken}
 
 	if !p.expectPeek(token.NUMERO) {
@@ -232,10 +400,219 @@ func (p *Parser) parseForStatement() *ast.ForStatement {
 	return stmt
 }
 
-func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
+func (p *Parser) parseForEachStatement() ast.Statement {
+	stmt := &ast.ForEachStatement{Token: p.curToken}
+	p.nextToken()
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	stmt.Variable = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if !p.expectPeek(token.EM) {
+		return nil
+	}
+
+	p.nextToken()
+	stmt.Iterable = p.parseExpression(LOWEST)
+
+	stmt.Body = p.parseBlockStatement()
+
+	return stmt
+}
+
+func (p *Parser) parseRecordStatement() ast.Statement {
+	stmt := &ast.RecordStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	p.nextToken()
+
+	for !p.curTokenIs(token.FIM) {
+		switch p.curToken.Type {
+		case token.EOF:
+			p.peekError(token.FIM)
+			return nil
+		case token.NEWLINE, token.SEMICOLON:
+			p.nextToken()
+			continue
+		}
+
+		field := p.parseRecordField()
+		if field == nil {
+			return nil
+		}
+		stmt.Fields = append(stmt.Fields, field)
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseClassStatement() ast.Statement {
+	stmt := &ast.ClassStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if p.peekTokenIs(token.HERDA) {
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Superclass = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	}
+
+	p.nextToken()
+
+	for !p.curTokenIs(token.FIM) {
+		switch p.curToken.Type {
+		case token.EOF:
+			p.peekError(token.FIM)
+			return nil
+		case token.NEWLINE, token.SEMICOLON:
+			p.nextToken()
+			continue
+		case token.FUNCAO, token.FUNÇÃO:
+			method, ok := p.parseFunctionLiteral().(*ast.FunctionLiteral)
+			if !ok || method == nil {
+				return nil
+			}
+			if method.Name == nil {
+				p.errors = append(p.errors, fmt.Sprintf("método sem nome na classe %s", stmt.Name.Value))
+				return nil
+			}
+			stmt.Methods = append(stmt.Methods, method)
+		default:
+			field := p.parseRecordField()
+			if field == nil {
+				return nil
+			}
+			stmt.Fields = append(stmt.Fields, field)
+		}
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// parseEnumStatement aceita os membros separados por vírgulas ou por linhas.
+func (p *Parser) parseEnumStatement() ast.Statement {
+	stmt := &ast.EnumStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	p.nextToken()
+
+	seen := map[string]bool{}
+	for !p.curTokenIs(token.FIM) {
+		switch p.curToken.Type {
+		case token.EOF:
+			p.peekError(token.FIM)
+			return nil
+		case token.NEWLINE, token.COMMA:
+			p.nextToken()
+			continue
+		case token.IDENT:
+		default:
+			p.errors = append(p.errors, fmt.Sprintf("esperado nome de membro em %s, recebido %s", stmt.Name.Value, p.curToken.Literal))
+			return nil
+		}
+
+		if seen[p.curToken.Literal] {
+			p.errors = append(p.errors, fmt.Sprintf("membro repetido em %s: %s", stmt.Name.Value, p.curToken.Literal))
+			return nil
+		}
+		seen[p.curToken.Literal] = true
+
+		stmt.Members = append(stmt.Members, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// parseRecordField lê "[tipo] nome [= padrão]". O tipo pode ser um dos tipos
+// de declaração ou o nome de outro registro.
+func (p *Parser) parseRecordField() *ast.RecordField {
+	field := &ast.RecordField{Token: p.curToken}
+
+	if p.peekTokenIs(token.IDENT) {
+		if !isDeclarationType(p.curToken.Type) && !p.curTokenIs(token.IDENT) {
+			p.errors = append(p.errors, fmt.Sprintf("tipo de campo inválido: %s", p.curToken.Literal))
+			return nil
+		}
+		field.Type = p.curToken.Literal
+		p.nextToken()
+	}
+
+	if !p.curTokenIs(token.IDENT) {
+		p.errors = append(p.errors, fmt.Sprintf("esperado nome de campo, recebido %s", p.curToken.Type))
+		return nil
+	}
+
+	field.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if p.peekTokenIs(token.ASSIGN) {
+		p.nextToken()
+		p.nextToken()
+		field.Default = p.parseExpression(LOWEST)
+	}
+
+	return field
+}
+
+// isTypeName aceita os tipos de declaração, "funcao" e nomes de registros,
+// classes e enumerações.
+func isTypeName(tok token.Token) bool {
+	switch tok.Type {
+	case token.IDENT, token.FUNCAO, token.FUNÇÃO:
+		return true
+	default:
+		return isDeclarationType(tok.Type)
+	}
+}
+
+func isDeclarationType(t token.TokenType) bool {
+	switch t {
+	case token.NUMERO, token.TEXTO, token.BOOLEANO, token.LISTA, token.MAPA, token.CONJUNTO:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *Parser) parseExpressionStatement() ast.Statement {
 	stmt := &ast.ExpressionStatement{Token: p.curToken}
 	stmt.Expression = p.parseExpression(LOWEST)
 
+	// uma lista sem parênteses só é aceita como alvo de atribuição: a, b = b, a
+	if p.peekTokenIs(token.COMMA) {
+		targets := p.parseTupleRest(stmt.Token, stmt.Expression, LOWEST)
+		if targets == nil {
+			return nil
+		}
+		if p.peekToken.Type != token.ASSIGN {
+			p.errors = append(p.errors, fmt.Sprintf("lista separada por vírgulas fora de uma atribuição: %s", targets.String()))
+			return nil
+		}
+		return p.parseAssignStatement(targets)
+	}
+
+	if p.peekToken.Type == token.ASSIGN {
+		return p.parseAssignStatement(stmt.Expression)
+	}
+
 	if p.peekToken.Type == token.SEMICOLON {
 		p.nextToken()
 	}
@@ -243,6 +620,47 @@ func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 	return stmt
 }
 
+func (p *Parser) parseAssignStatement(target ast.Expression) ast.Statement {
+	p.nextToken()
+	stmt := &ast.AssignStatement{Token: p.curToken, Target: target}
+
+	p.nextToken()
+	stmt.Value = p.parseExpressionOrTuple(LOWEST)
+
+	for p.peekToken.Type == token.SEMICOLON {
+		p.nextToken()
+	}
+
+	if !isAssignable(target) {
+		if target != nil {
+			p.errors = append(p.errors, fmt.Sprintf("não é possível atribuir a %s", target.String()))
+		}
+		return nil
+	}
+
+	return stmt
+}
+
+func isAssignable(target ast.Expression) bool {
+	switch target := target.(type) {
+	case *ast.Identifier:
+		return true
+	case *ast.IndexExpression:
+		return !target.Optional
+	case *ast.MemberExpression:
+		return true
+	case *ast.TupleLiteral:
+		for _, element := range target.Elements {
+			if !isAssignable(element) {
+				return false
+			}
+		}
+		return len(target.Elements) > 0
+	default:
+		return false
+	}
+}
+
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	block := &ast.BlockStatement{Token: p.curToken}
 	block.Statements = []ast.Statement{}
@@ -250,7 +668,8 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	p.nextToken()
 
 	for p.curToken.Type != token.FIM && p.curToken.Type != token.EOF &&
-		p.curToken.Type != token.SENAO && p.curToken.Type != token.SENÃO {
+		p.curToken.Type != token.SENAO && p.curToken.Type != token.SENÃO &&
+		p.curToken.Type != token.CASO && p.curToken.Type != token.PADRAO && p.curToken.Type != token.PADRÃO {
 		if p.curToken.Type == token.NEWLINE {
 			p.nextToken()
 			continue
@@ -296,6 +715,12 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	lit := &ast.IntegerLiteral{Token: p.curToken}
 
 	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		if bigValue, ok := new(big.Int).SetString(p.curToken.Literal, 0); ok {
+			lit.Big = bigValue
+			return lit
+		}
+	}
 	if err != nil {
 		msg := fmt.Sprintf("não foi possível converter %q para inteiro", p.curToken.Literal)
 		p.errors = append(p.errors, msg)
@@ -328,6 +753,10 @@ func (p *Parser) parseBoolean() ast.Expression {
 	return &ast.Boolean{Token: p.curToken, Value: p.curTokenIs(token.VERDADEIRO)}
 }
 
+func (p *Parser) parseNullLiteral() ast.Expression {
+	return &ast.NullLiteral{Token: p.curToken}
+}
+
 func (p *Parser) parsePrefixExpression() ast.Expression {
 	expression := &ast.PrefixExpression{
 		Token:    p.curToken,
@@ -355,11 +784,36 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	return expression
 }
 
+func (p *Parser) parseLogicalExpression(left ast.Expression) ast.Expression {
+	expression := &ast.LogicalExpression{
+		Token:    p.curToken,
+		Left:     left,
+		Operator: p.curToken.Literal,
+	}
+
+	precedence := p.curPrecedence()
+	p.nextToken()
+	expression.Right = p.parseExpression(precedence)
+
+	return expression
+}
+
 func (p *Parser) parseGroupedExpression() ast.Expression {
+	tok := p.curToken
+
+	if p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		return &ast.TupleLiteral{Token: tok, Elements: []ast.Expression{}}
+	}
+
 	p.nextToken()
 
 	exp := p.parseExpression(LOWEST)
 
+	if p.peekTokenIs(token.COMMA) {
+		return p.parseTupleLiteral(tok, exp)
+	}
+
 	if !p.expectPeek(token.RPAREN) {
 		return nil
 	}
@@ -367,6 +821,26 @@ func (p *Parser) parseGroupedExpression() ast.Expression {
 	return exp
 }
 
+// parseTupleLiteral continua "(a, b)" e "(a,)" depois do primeiro elemento.
+func (p *Parser) parseTupleLiteral(tok token.Token, first ast.Expression) ast.Expression {
+	tuple := &ast.TupleLiteral{Token: tok, Elements: []ast.Expression{first}}
+
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		if p.peekTokenIs(token.RPAREN) {
+			break
+		}
+		p.nextToken()
+		tuple.Elements = append(tuple.Elements, p.parseExpression(LOWEST))
+	}
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	return tuple
+}
+
 func (p *Parser) parseIfExpression() ast.Expression {
 	expression := &ast.IfExpression{Token: p.curToken}
 
@@ -383,6 +857,133 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	return expression
 }
 
+func (p *Parser) parseSwitchExpression() ast.Expression {
+	exp := &ast.SwitchExpression{Token: p.curToken}
+
+	p.nextToken()
+	exp.Subject = p.parseExpression(LOWEST)
+	p.nextToken()
+
+	for !p.curTokenIs(token.FIM) {
+		switch p.curToken.Type {
+		case token.EOF:
+			p.peekError(token.FIM)
+			return nil
+		case token.NEWLINE:
+			p.nextToken()
+		case token.CASO:
+			if exp.Default != nil {
+				p.errors = append(p.errors, "caso depois de padrão em escolha")
+				return nil
+			}
+			switchCase := p.parseSwitchCase()
+			if switchCase == nil {
+				return nil
+			}
+			exp.Cases = append(exp.Cases, switchCase)
+		case token.PADRAO, token.PADRÃO:
+			if exp.Default != nil {
+				p.errors = append(p.errors, "padrão repetido em escolha")
+				return nil
+			}
+			exp.Default = p.parseBlockStatement()
+		default:
+			p.errors = append(p.errors, fmt.Sprintf("esperado caso ou padrão em escolha, recebido %s", p.curToken.Literal))
+			return nil
+		}
+	}
+
+	return exp
+}
+
+func (p *Parser) parseSwitchCase() *ast.SwitchCase {
+	switchCase := &ast.SwitchCase{Token: p.curToken}
+
+	p.nextToken()
+	switchCase.Patterns = append(switchCase.Patterns, p.parsePattern())
+
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		p.nextToken()
+		switchCase.Patterns = append(switchCase.Patterns, p.parsePattern())
+	}
+
+	for _, pattern := range switchCase.Patterns {
+		if !isValidPattern(pattern) {
+			if pattern != nil {
+				p.errors = append(p.errors, fmt.Sprintf("padrão inválido em caso: %s", pattern.String()))
+			}
+			return nil
+		}
+	}
+
+	if p.peekTokenIs(token.SE) {
+		p.nextToken()
+		p.nextToken()
+		switchCase.Guard = p.parseExpression(LOWEST)
+	}
+
+	switchCase.Body = p.parseBlockStatement()
+
+	return switchCase
+}
+
+func (p *Parser) parsePattern() ast.Expression {
+	pattern := p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.ATÉ) || p.peekTokenIs(token.ATE) {
+		p.nextToken()
+		rangePattern := &ast.RangePattern{Token: p.curToken, Low: pattern}
+		p.nextToken()
+		rangePattern.High = p.parseExpression(LOWEST)
+		return rangePattern
+	}
+
+	return pattern
+}
+
+// isValidPattern aceita literais, nomes (que capturam o valor; "_" ignora),
+// valores qualificados como Status.PAGO, intervalos e listas, tuplas e mapas
+// formados por outros padrões.
+func isValidPattern(pattern ast.Expression) bool {
+	switch pattern := pattern.(type) {
+	case *ast.IntegerLiteral, *ast.FloatLiteral, *ast.StringLiteral, *ast.Boolean,
+		*ast.NullLiteral, *ast.Identifier, *ast.MemberExpression:
+		return true
+	case *ast.PrefixExpression:
+		switch pattern.Right.(type) {
+		case *ast.IntegerLiteral, *ast.FloatLiteral:
+			return pattern.Operator == "-"
+		}
+		return false
+	case *ast.RangePattern:
+		return isValidPattern(pattern.Low) && isValidPattern(pattern.High)
+	case *ast.ArrayLiteral:
+		for _, element := range pattern.Elements {
+			if !isValidPattern(element) {
+				return false
+			}
+		}
+		return true
+	case *ast.TupleLiteral:
+		for _, element := range pattern.Elements {
+			if !isValidPattern(element) {
+				return false
+			}
+		}
+		return true
+	case *ast.HashLiteral:
+		for _, pair := range pattern.Pairs {
+			if !isValidPattern(pair.Value) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
 func (p *Parser) parseFunctionLiteral() ast.Expression {
 	lit := &ast.FunctionLiteral{Token: p.curToken}
 
@@ -398,6 +999,19 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 
 	lit.Parameters = p.parseFunctionParameters()
 
+	if p.peekTokenIs(token.ARROW) {
+		p.nextToken()
+		p.nextToken()
+		if !isTypeName(p.curToken) {
+			p.errors = append(p.errors, fmt.Sprintf("tipo de retorno inválido: %s", p.curToken.Literal))
+		}
+		lit.ReturnType = p.curToken.Literal
+	}
+
+	if p.peekTokenIs(token.ASSIGN) {
+		return p.parseConciseFunctionBody(lit)
+	}
+
 
 	for p.peekToken.Type == token.NEWLINE {
 		p.nextToken()
@@ -408,8 +1022,29 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 	return lit
 }
 
-func (p *Parser) parseFunctionParameters() []*ast.Identifier {
-	identifiers := []*ast.Identifier{}
+// parseConciseFunctionBody lê o corpo da forma curta funcao(x) = expressão,
+// equivalente a um bloco com um único retorne.
+func (p *Parser) parseConciseFunctionBody(lit *ast.FunctionLiteral) ast.Expression {
+	p.nextToken()
+	assign := p.curToken
+	p.nextToken()
+
+	value := p.parseExpression(LOWEST)
+	if value == nil {
+		return nil
+	}
+
+	ret := &ast.ReturnStatement{
+		Token:       token.Token{Type: token.RETORNE, Literal: "retorne", Line: assign.Line, Column: assign.Column},
+		ReturnValue: value,
+	}
+	lit.Body = &ast.BlockStatement{Token: assign, Statements: []ast.Statement{ret}}
+	lit.Concise = true
+	return lit
+}
+
+func (p *Parser) parseFunctionParameters() []*ast.Parameter {
+	parameters := []*ast.Parameter{}
 
 
 	for p.peekToken.Type == token.NEWLINE {
@@ -418,19 +1053,16 @@ func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 
 	if p.peekToken.Type == token.RPAREN {
 		p.nextToken()
-		return identifiers
+		return parameters
 	}
 
 	p.nextToken()
-
-	ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-	identifiers = append(identifiers, ident)
+	parameters = append(parameters, p.parseFunctionParameter())
 
 	for p.peekToken.Type == token.COMMA {
 		p.nextToken()
 		p.nextToken()
-		ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-		identifiers = append(identifiers, ident)
+		parameters = append(parameters, p.parseFunctionParameter())
 	}
 
 
@@ -442,7 +1074,65 @@ func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 		return nil
 	}
 
-	return identifiers
+	p.checkParameterOrder(parameters)
+
+	return parameters
+}
+
+func (p *Parser) parseFunctionParameter() *ast.Parameter {
+	param := &ast.Parameter{Token: p.curToken}
+
+	if !p.curTokenIs(token.ELLIPSIS) && (p.peekTokenIs(token.IDENT) || p.peekTokenIs(token.ELLIPSIS)) {
+		if !isTypeName(p.curToken) {
+			p.errors = append(p.errors, fmt.Sprintf("tipo de parâmetro inválido: %s", p.curToken.Literal))
+		}
+		param.Type = p.curToken.Literal
+		p.nextToken()
+	}
+
+	if p.curTokenIs(token.ELLIPSIS) {
+		param.Variadic = true
+		p.nextToken()
+	}
+
+	param.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	if !p.curTokenIs(token.IDENT) {
+		p.errors = append(p.errors, fmt.Sprintf("esperado nome de parâmetro, recebido %s", p.curToken.Literal))
+		return param
+	}
+
+	if !param.Variadic && p.peekTokenIs(token.ASSIGN) {
+		p.nextToken()
+		p.nextToken()
+		param.Default = p.parseExpression(LOWEST)
+	}
+
+	return param
+}
+
+// checkParameterOrder exige parâmetros obrigatórios antes dos que têm valor
+// padrão e no máximo um variádico, sempre por último.
+func (p *Parser) checkParameterOrder(parameters []*ast.Parameter) {
+	seen := map[string]bool{}
+	hasDefault := false
+
+	for i, param := range parameters {
+		if seen[param.Name.Value] {
+			p.errors = append(p.errors, fmt.Sprintf("parâmetro repetido: %s", param.Name.Value))
+		}
+		seen[param.Name.Value] = true
+
+		switch {
+		case param.Variadic:
+			if i != len(parameters)-1 {
+				p.errors = append(p.errors, fmt.Sprintf("parâmetro variádico ...%s deve ser o último", param.Name.Value))
+			}
+		case param.Default != nil:
+			hasDefault = true
+		case hasDefault:
+			p.errors = append(p.errors, fmt.Sprintf("parâmetro obrigatório %s depois de parâmetro com valor padrão", param.Name.Value))
+		}
+	}
 }
 
 func (p *Parser) parseFunctionStatement() ast.Statement {
@@ -485,7 +1175,61 @@ func (p *Parser) parseIncludeStatement() ast.Statement {
 
 func (p *Parser) parseCallExpression(fn ast.Expression) ast.Expression {
 	exp := &ast.CallExpression{Token: p.curToken, Function: fn}
-	exp.Arguments = p.parseExpressionList(token.RPAREN)
+	exp.Arguments = p.parseCallArguments()
+	return exp
+}
+
+// parseCallArguments aceita argumentos posicionais seguidos de argumentos
+// nomeados ("nome: valor").
+func (p *Parser) parseCallArguments() []ast.Expression {
+	args := []ast.Expression{}
+
+	if p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		return args
+	}
+
+	named := false
+	for {
+		p.nextToken()
+
+		if p.curTokenIs(token.IDENT) && p.peekTokenIs(token.COLON) {
+			arg := &ast.NamedArgument{Token: p.curToken}
+			arg.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			p.nextToken()
+			p.nextToken()
+			arg.Value = p.parseExpression(LOWEST)
+			args = append(args, arg)
+			named = true
+		} else {
+			arg := p.parseExpression(LOWEST)
+			if named {
+				p.errors = append(p.errors, "argumento posicional depois de argumento nomeado")
+			}
+			args = append(args, arg)
+		}
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	return args
+}
+
+func (p *Parser) parseMemberExpression(left ast.Expression) ast.Expression {
+	exp := &ast.MemberExpression{Token: p.curToken, Object: left}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	exp.Property = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 	return exp
 }
 
@@ -521,7 +1265,7 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 
 func (p *Parser) parseHashLiteral() ast.Expression {
 	hash := &ast.HashLiteral{Token: p.curToken}
-	hash.Pairs = make(map[ast.Expression]ast.Expression)
+	hash.Pairs = []ast.HashLiteralPair{}
 
 	for p.peekToken.Type != token.RBRACE {
 		p.nextToken()
@@ -534,7 +1278,7 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 		p.nextToken()
 		value := p.parseExpression(LOWEST)
 
-		hash.Pairs[key] = value
+		hash.Pairs = append(hash.Pairs, ast.HashLiteralPair{Key: key, Value: value})
 
 		if p.peekToken.Type != token.RBRACE && !p.expectPeek(token.COMMA) {
 			return nil
@@ -548,12 +1292,34 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 	return hash
 }
 
+// parseSetLiteral trata conjunto{1, 2}. Em conjunto(lista) a palavra é só o
+// nome da função embutida que constrói o conjunto.
+func (p *Parser) parseSetLiteral() ast.Expression {
+	if !p.peekTokenIs(token.LBRACE) {
+		return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	}
+
+	set := &ast.SetLiteral{Token: p.curToken}
+	p.nextToken()
+	set.Elements = p.parseExpressionList(token.RBRACE)
+	return set
+}
+
 func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
-	exp := &ast.IndexExpression{Token: p.curToken, Left: left}
+	exp := &ast.IndexExpression{Token: p.curToken, Left: left, Optional: p.curTokenIs(token.OPTIONAL_INDEX)}
 
 	p.nextToken()
+	if p.curTokenIs(token.COLON) {
+		return p.parseSliceExpression(exp)
+	}
+
 	exp.Index = p.parseExpression(LOWEST)
 
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		return p.parseSliceExpression(exp)
+	}
+
 	if !p.expectPeek(token.RBRACKET) {
 		return nil
 	}
@@ -561,6 +1327,34 @@ func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	return exp
 }
 
+func (p *Parser) parseSliceExpression(index *ast.IndexExpression) ast.Expression {
+	slice := &ast.SliceExpression{
+		Token:    index.Token,
+		Left:     index.Left,
+		Start:    index.Index,
+		Optional: index.Optional,
+	}
+
+	if !p.peekTokenIs(token.COLON) && !p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+		slice.End = p.parseExpression(LOWEST)
+	}
+
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		if !p.peekTokenIs(token.RBRACKET) {
+			p.nextToken()
+			slice.Step = p.parseExpression(LOWEST)
+		}
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+
+	return slice
+}
+
 func (p *Parser) parseImprimirCall() ast.Expression {
 
 	ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
@@ -0,0 +1,30 @@
+package parser
+
+import (
+	"testing"
+
+	"sovylang/internal/ast"
+	"sovylang/internal/lexer"
+)
+
+func TestBareCommaListOnlyBeforeAssign(t *testing.T) {
+	p := New(lexer.New("a, b = b, a"))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("erros de sintaxe: %v", p.Errors())
+	}
+	if len(program.Statements) != 1 {
+		t.Fatalf("esperada 1 instrução, recebidas %d", len(program.Statements))
+	}
+	if _, ok := program.Statements[0].(*ast.AssignStatement); !ok {
+		t.Fatalf("esperado AssignStatement, recebido %T", program.Statements[0])
+	}
+
+	for _, input := range []string{"imprimir 1, 2", "a, \n", "a, )", "retorne 1, "} {
+		p = New(lexer.New(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("esperado erro de sintaxe para %q", input)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+// Package resolver implementa a passagem executada entre a análise sintática
+// e a execução: cada identificador recebe a profundidade do quadro em que seu
+// valor é guardado e a posição dentro dele, e nomes que não existem em
+// nenhum escopo são informados antes de o programa rodar.
+package resolver
+
+import (
+	"fmt"
+	"sort"
+
+	"sovylang/internal/ast"
+	"sovylang/internal/evaluator"
+	"sovylang/internal/library"
+	"sovylang/internal/token"
+)
+
+// scope espelha um quadro criado pelo interpretador: o programa, uma chamada
+// de função ou de método, um ramo de se, uma volta de para ou um caso.
+type scope struct {
+	layout *ast.Scope
+	outer  *scope
+}
+
+type resolver struct {
+	scope  *scope
+	errors []string
+}
+
+// Resolve preenche as disposições dos escopos e as posições dos
+// identificadores de program. Devolve um erro para cada nome não encontrado.
+func Resolve(program *ast.Program) []string {
+	r := &resolver{}
+	program.Scope = ast.NewScope()
+	r.push(program.Scope)
+	r.resolveStatements(program.Statements)
+	r.pop()
+	return r.errors
+}
+
+func (r *resolver) push(layout *ast.Scope) {
+	r.scope = &scope{layout: layout, outer: r.scope}
+}
+
+func (r *resolver) pop() {
+	r.scope = r.scope.outer
+}
+
+func (r *resolver) declare(name string) {
+	r.scope.layout.Declare(name)
+}
+
+// resolveStatements declara primeiro tudo o que o escopo declara, para que
+// funções possam usar nomes definidos depois delas.
+func (r *resolver) resolveStatements(statements []ast.Statement) {
+	for _, statement := range statements {
+		r.hoist(statement)
+	}
+	for _, statement := range statements {
+		r.resolveStatement(statement)
+	}
+}
+
+func (r *resolver) hoist(statement ast.Statement) {
+	switch node := statement.(type) {
+	case *ast.VarStatement:
+		r.declare(node.Name.Value)
+	case *ast.DestructureStatement:
+		for _, name := range node.Names {
+			r.declare(name.Value)
+		}
+	case *ast.ExpressionStatement:
+		if fn, ok := node.Expression.(*ast.FunctionLiteral); ok && fn.Name != nil {
+			r.declare(fn.Name.Value)
+		}
+	case *ast.RecordStatement:
+		r.declare(node.Name.Value)
+	case *ast.ClassStatement:
+		r.declare(node.Name.Value)
+	case *ast.EnumStatement:
+		r.declare(node.Name.Value)
+	case *ast.IncludeStatement:
+		lib, ok := library.Lookup(node.Library.Value)
+		if !ok {
+			r.errorf(node.Library.Token, "biblioteca '%s' não encontrada", node.Library.Value)
+			return
+		}
+		names := []string{}
+		for name := range lib.GetBuiltins() {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			r.declare(name)
+		}
+	}
+}
+
+func (r *resolver) resolveStatement(statement ast.Statement) {
+	switch node := statement.(type) {
+	case *ast.VarStatement:
+		r.resolveExpression(node.Value)
+	case *ast.AssignStatement:
+		r.resolveExpression(node.Target)
+		r.resolveExpression(node.Value)
+	case *ast.DestructureStatement:
+		r.resolveExpression(node.Value)
+	case *ast.ReturnStatement:
+		r.resolveExpression(node.ReturnValue)
+	case *ast.ExpressionStatement:
+		r.resolveExpression(node.Expression)
+	case *ast.ForStatement:
+		r.resolveExpression(node.Start)
+		r.resolveExpression(node.End)
+		r.resolveBlock(node.Body, node.Variable.Value)
+	case *ast.ForEachStatement:
+		r.resolveExpression(node.Iterable)
+		r.resolveBlock(node.Body, node.Variable.Value)
+	case *ast.RecordStatement:
+		for _, field := range node.Fields {
+			r.resolveExpression(field.Default)
+		}
+	case *ast.ClassStatement:
+		if node.Superclass != nil {
+			r.resolveIdentifier(node.Superclass)
+		}
+		for _, field := range node.Fields {
+			r.resolveExpression(field.Default)
+		}
+		for _, method := range node.Methods {
+			r.push(ast.MethodScope)
+			r.resolveFunction(method)
+			r.pop()
+		}
+	}
+}
+
+// resolveBlock resolve um bloco que ganha quadro próprio; names são ligados
+// pelo interpretador antes de o bloco começar, como a variável de um para.
+func (r *resolver) resolveBlock(block *ast.BlockStatement, names ...string) {
+	if block == nil {
+		return
+	}
+	block.Scope = ast.NewScope(names...)
+	r.push(block.Scope)
+	r.resolveStatements(block.Statements)
+	r.pop()
+}
+
+func (r *resolver) resolveFunction(fn *ast.FunctionLiteral) {
+	if fn.Body == nil {
+		return
+	}
+	fn.Body.Scope = ast.NewScope()
+	r.push(fn.Body.Scope)
+	for _, param := range fn.Parameters {
+		r.declare(param.Name.Value)
+	}
+	for _, param := range fn.Parameters {
+		r.resolveExpression(param.Default)
+	}
+	r.resolveStatements(fn.Body.Statements)
+	r.pop()
+}
+
+func (r *resolver) resolveExpression(expression ast.Expression) {
+	switch node := expression.(type) {
+	case nil:
+		return
+	case *ast.Identifier:
+		r.resolveIdentifier(node)
+	case *ast.PrefixExpression:
+		r.resolveExpression(node.Right)
+	case *ast.InfixExpression:
+		r.resolveExpression(node.Left)
+		r.resolveExpression(node.Right)
+	case *ast.LogicalExpression:
+		r.resolveExpression(node.Left)
+		r.resolveExpression(node.Right)
+	case *ast.IfExpression:
+		r.resolveExpression(node.Condition)
+		r.resolveBlock(node.Consequence)
+		r.resolveBlock(node.Alternative)
+	case *ast.SwitchExpression:
+		r.resolveSwitch(node)
+	case *ast.FunctionLiteral:
+		if node.Name != nil {
+			r.declare(node.Name.Value)
+		}
+		r.resolveFunction(node)
+	case *ast.CallExpression:
+		r.resolveExpression(node.Function)
+		for _, arg := range node.Arguments {
+			r.resolveExpression(arg)
+		}
+	case *ast.NamedArgument:
+		r.resolveExpression(node.Value)
+	case *ast.MemberExpression:
+		r.resolveExpression(node.Object)
+	case *ast.ArrayLiteral:
+		r.resolveExpressions(node.Elements)
+	case *ast.SetLiteral:
+		r.resolveExpressions(node.Elements)
+	case *ast.TupleLiteral:
+		r.resolveExpressions(node.Elements)
+	case *ast.HashLiteral:
+		for _, pair := range node.Pairs {
+			r.resolveExpression(pair.Key)
+			r.resolveExpression(pair.Value)
+		}
+	case *ast.IndexExpression:
+		r.resolveExpression(node.Left)
+		r.resolveExpression(node.Index)
+	case *ast.SliceExpression:
+		r.resolveExpression(node.Left)
+		r.resolveExpression(node.Start)
+		r.resolveExpression(node.End)
+		r.resolveExpression(node.Step)
+	case *ast.RangePattern:
+		r.resolveExpression(node.Low)
+		r.resolveExpression(node.High)
+	}
+}
+
+func (r *resolver) resolveExpressions(expressions []ast.Expression) {
+	for _, expression := range expressions {
+		r.resolveExpression(expression)
+	}
+}
+
+// resolveSwitch dá a cada caso um quadro com os nomes capturados pelos
+// padrões; a guarda e o corpo do caso são resolvidos nele.
+func (r *resolver) resolveSwitch(node *ast.SwitchExpression) {
+	r.resolveExpression(node.Subject)
+
+	for _, switchCase := range node.Cases {
+		if switchCase.Body == nil {
+			continue
+		}
+		switchCase.Body.Scope = ast.NewScope()
+		r.push(switchCase.Body.Scope)
+		for _, pattern := range switchCase.Patterns {
+			r.resolvePattern(pattern)
+		}
+		r.resolveExpression(switchCase.Guard)
+		r.resolveStatements(switchCase.Body.Statements)
+		r.pop()
+	}
+
+	r.resolveBlock(node.Default)
+}
+
+func (r *resolver) resolvePattern(pattern ast.Expression) {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		if pattern.Value != "_" {
+			r.declare(pattern.Value)
+		}
+	case *ast.ArrayLiteral:
+		for _, element := range pattern.Elements {
+			r.resolvePattern(element)
+		}
+	case *ast.TupleLiteral:
+		for _, element := range pattern.Elements {
+			r.resolvePattern(element)
+		}
+	case *ast.HashLiteral:
+		for _, pair := range pattern.Pairs {
+			r.resolveExpression(pair.Key)
+			r.resolvePattern(pair.Value)
+		}
+	default:
+		r.resolveExpression(pattern)
+	}
+}
+
+// resolveIdentifier procura o nome do escopo mais interno para o mais
+// externo. Nomes declarados no programa têm prioridade sobre as funções
+// built-in de mesmo nome.
+func (r *resolver) resolveIdentifier(id *ast.Identifier) {
+	id.Resolved, id.Builtin = false, false
+
+	depth := 0
+	for s := r.scope; s != nil; s = s.outer {
+		if slot, ok := s.layout.Slots[id.Value]; ok {
+			id.Resolved, id.Depth, id.Slot = true, depth, slot
+			return
+		}
+		depth++
+	}
+
+	if evaluator.IsBuiltin(id.Value) {
+		id.Builtin = true
+		return
+	}
+
+	r.errorf(id.Token, "identificador não encontrado: %s", id.Value)
+}
+
+func (r *resolver) errorf(tok token.Token, format string, args ...interface{}) {
+	position := fmt.Sprintf("%d:%d: ", tok.Line, tok.Column)
+	r.errors = append(r.errors, position+fmt.Sprintf(format, args...))
+}
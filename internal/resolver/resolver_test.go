@@ -0,0 +1,185 @@
+package resolver
+
+import (
+	"reflect"
+	"testing"
+
+	"sovylang/internal/ast"
+	"sovylang/internal/evaluator"
+	"sovylang/internal/lexer"
+	"sovylang/internal/parser"
+)
+
+func TestShadowedGlobalReadBeforeLocalDeclaration(t *testing.T) {
+	input := `
+numero x = 10
+funcao f()
+    lista antes = [x]
+    numero x = 2
+    retorne [antes[0], x]
+fim
+f()
+`
+	program := resolve(t, input)
+
+	fn := program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	read := fn.Body.Statements[0].(*ast.VarStatement).Value.(*ast.ArrayLiteral).Elements[0].(*ast.Identifier)
+	// o x local é declarado depois da leitura, mas pertence ao quadro da função
+	expectSlot(t, read, 0, fn.Body.Scope.Slots["x"])
+
+	expectSameResult(t, input, "[10, 2]")
+}
+
+func TestClosuresCapturePerIteration(t *testing.T) {
+	input := `
+lista fs = []
+para numero i = 1 ate 3
+    anexar(fs, funcao() = i * 10)
+fim
+para cada valor em ["a", "b"]
+    anexar(fs, funcao() = valor)
+fim
+mapear(fs, funcao(f) = f())
+`
+	program := resolve(t, input)
+
+	loop := program.Statements[1].(*ast.ForStatement)
+	closure := loop.Body.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.CallExpression).Arguments[1].(*ast.FunctionLiteral)
+	read := closure.Body.Statements[0].(*ast.ReturnStatement).ReturnValue.(*ast.InfixExpression).Left.(*ast.Identifier)
+	expectSlot(t, read, 1, 0)
+
+	expectSameResult(t, input, "[10, 20, 30, a, b]")
+}
+
+func TestMethodReceiverDepth(t *testing.T) {
+	input := `
+classe A
+    funcao nome()
+        retorne "A"
+    fim
+fim
+classe B herda A
+    texto sufixo = "!"
+    funcao nome()
+        retorne super.nome() + este.sufixo
+    fim
+fim
+B().nome()
+`
+	program := resolve(t, input)
+
+	method := program.Statements[1].(*ast.ClassStatement).Methods[0]
+	sum := method.Body.Statements[0].(*ast.ReturnStatement).ReturnValue.(*ast.InfixExpression)
+	super := sum.Left.(*ast.CallExpression).Function.(*ast.MemberExpression).Object.(*ast.Identifier)
+	este := sum.Right.(*ast.MemberExpression).Object.(*ast.Identifier)
+	expectSlot(t, este, 1, ast.MethodScope.Slots["este"])
+	expectSlot(t, super, 1, ast.MethodScope.Slots["super"])
+
+	expectSameResult(t, input, "A!")
+}
+
+func TestBlockFrames(t *testing.T) {
+	input := `
+funcao classificar(v)
+    retorne escolha v
+        caso [a, b] se a > b
+            a - b
+        caso (x, _)
+            numero dobro = x * 2
+            dobro
+        padrão
+            numero zero = 0
+            se v == nulo
+                numero um = 1
+                zero + um
+            senao
+                zero
+            fim
+    fim
+fim
+funcao soma(inicial, ...numeros)
+    numero total = inicial + base
+    para cada n em numeros
+        total = total + n
+    fim
+    retorne total
+fim
+numero base = 100
+[classificar([5, 2]), classificar((4, 0)), classificar(nulo), classificar("x"), soma(1, 2, 3)]
+`
+	resolve(t, input)
+	expectSameResult(t, input, "[3, 8, 1, 0, 106]")
+}
+
+func TestUnresolvedNames(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"numero a = 1\nimprimir(b + a)", []string{"2:10: identificador não encontrado: b"}},
+		{"funcao f()\n    retorne este\nfim", []string{"2:13: identificador não encontrado: este"}},
+		{"se verdadeiro\n    numero x = 1\nfim\nimprimir(x)", []string{"4:10: identificador não encontrado: x"}},
+		{"sovy sstring include\nmaiusculo(\"a\")", []string{
+			"1:6: biblioteca 'sstring' não encontrada",
+			"2:1: identificador não encontrado: maiusculo",
+		}},
+		{"sovy smath include\nraiz(tamanho([1]))", nil},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("%q: erros de sintaxe: %v", tt.input, p.Errors())
+		}
+		errors := Resolve(program)
+		if !reflect.DeepEqual(errors, tt.expected) {
+			t.Errorf("%q: erros %q, esperado %q", tt.input, errors, tt.expected)
+		}
+	}
+}
+
+func resolve(t *testing.T, input string) *ast.Program {
+	t.Helper()
+	program := parse(t, input)
+	if errors := Resolve(program); len(errors) != 0 {
+		t.Fatalf("erros de resolução: %v", errors)
+	}
+	return program
+}
+
+func parse(t *testing.T, input string) *ast.Program {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("erros de sintaxe: %v", p.Errors())
+	}
+	return program
+}
+
+func expectSlot(t *testing.T, id *ast.Identifier, depth, slot int) {
+	t.Helper()
+	if !id.Resolved {
+		t.Fatalf("%s não foi resolvido", id.Value)
+	}
+	if id.Depth != depth || id.Slot != slot {
+		t.Errorf("%s: profundidade %d, posição %d; esperado %d, %d", id.Value, id.Depth, id.Slot, depth, slot)
+	}
+}
+
+// expectSameResult executa o programa com e sem o resolvedor: os quadros
+// por posição devem dar o mesmo resultado da busca por nome.
+func expectSameResult(t *testing.T, input, expected string) {
+	t.Helper()
+	for _, resolved := range []bool{false, true} {
+		program := parse(t, input)
+		if resolved {
+			Resolve(program)
+		}
+		result := evaluator.New().Eval(program)
+		if result == nil || result.Inspect() != expected {
+			t.Errorf("resolvido=%v: resultado %v, esperado %s", resolved, result, expected)
+		}
+	}
+}
@@ -28,6 +28,16 @@ const (
 	SLASH    = "/"
 	PERCENT  = "%"
 
+	AMPERSAND   = "&"
+	PIPE        = "|"
+	CARET       = "^"
+	TILDE       = "~"
+	SHIFT_LEFT  = "<<"
+	SHIFT_RIGHT = ">>"
+
+	COALESCE       = "??"
+	OPTIONAL_INDEX = "?["
+
 	LT = "<"
 	GT = ">"
 	EQ = "=="
@@ -39,6 +49,9 @@ const (
 	COMMA     = ","
 	SEMICOLON = ";"
 	COLON     = ":"
+	DOT       = "."
+	ELLIPSIS  = "..."
+	ARROW     = "->"
 
 	LPAREN   = "("
 	RPAREN   = ")"
@@ -53,6 +66,19 @@ const (
 	BOOLEANO = "booleano"
 	LISTA    = "lista"
 	MAPA     = "mapa"
+	CONJUNTO = "conjunto"
+	REGISTRO = "registro"
+	CLASSE   = "classe"
+	HERDA    = "herda"
+	ESTE     = "este"
+	SUPER    = "super"
+	ENUMERACAO = "enumeracao"
+	ENUMERAÇÃO = "enumeração"
+	ESCOLHA    = "escolha"
+	CASO       = "caso"
+	PADRAO     = "padrao"
+	PADRÃO     = "padrão"
+	CONSTANTE  = "constante"
 	FUNÇÃO   = "função"
 	FUNCAO   = "funcao"
 	RETORNE  = "retorne"
@@ -60,6 +86,8 @@ const (
 	SENAO    = "senao"
 	SENÃO    = "senão"
 	PARA     = "para"
+	CADA     = "cada"
+	EM       = "em"
 	ATÉ      = "até"
 	ATE      = "ate"
 	FIM      = "fim"
@@ -69,6 +97,8 @@ const (
 	NAO      = "nao"
 	VERDADEIRO = "verdadeiro"
 	FALSO      = "falso"
+	NULO       = "nulo"
+	É          = "é"
 	IMPRIMIR   = "imprimir"
 	INCLUDE    = "include"
 	INSTALL    = "install"
@@ -83,6 +113,19 @@ var keywords = map[string]TokenType{
 	"booleano":   BOOLEANO,
 	"lista":      LISTA,
 	"mapa":       MAPA,
+	"conjunto":   CONJUNTO,
+	"registro":   REGISTRO,
+	"classe":     CLASSE,
+	"herda":      HERDA,
+	"este":       ESTE,
+	"super":      SUPER,
+	"enumeracao": ENUMERACAO,
+	"enumeração": ENUMERAÇÃO,
+	"escolha":    ESCOLHA,
+	"caso":       CASO,
+	"padrao":     PADRAO,
+	"padrão":     PADRÃO,
+	"constante":  CONSTANTE,
 	"função":     FUNÇÃO,
 	"funcao":     FUNCAO,
 	"retorne":    RETORNE,
@@ -90,6 +133,8 @@ var keywords = map[string]TokenType{
 	"senao":      SENAO,
 	"senão":      SENÃO,
 	"para":       PARA,
+	"cada":       CADA,
+	"em":         EM,
 	"até":        ATÉ,
 	"ate":        ATE,
 	"fim":        FIM,
@@ -99,6 +144,8 @@ var keywords = map[string]TokenType{
 	"nao":        NAO,
 	"verdadeiro": VERDADEIRO,
 	"falso":      FALSO,
+	"nulo":       NULO,
+	"é":          É,
 	"imprimir":   IMPRIMIR,
 	"include":    INCLUDE,
 	"install":    INSTALL,